@@ -13,11 +13,15 @@ import (
 var CLI struct {
 	LogLevel string `enum:"debug,info,warn,error" default:"warn"`
 
-	Pack     cmd.PackCmd   `cmd:"" help:"Packs the contents of a directory to a .dungeondraft_pack file, there must be a valid pack.json in the directory"`
-	Unpack   cmd.UnpackCmd `cmd:"" help:"Extracts the contesnts of a .dungeondraft_pack file"`
-	Generate cmd.GenCmd    `cmd:"" aliases:"gen" help:"Generate pack data and thumbtails"`
-	List     cmd.ListCmd   `cmd:"" aliases:"ls" help:"list resources in a .dungeondraft_pack file"`
-	Edit     cmd.EditCmd   `cmd:"" help:"Edit pack info, tags, and tag sets"`
+	Pack      cmd.PackCmd      `cmd:"" help:"Packs the contents of a directory to a .dungeondraft_pack file, there must be a valid pack.json in the directory"`
+	Unpack    cmd.UnpackCmd    `cmd:"" help:"Extracts the contesnts of a .dungeondraft_pack file"`
+	Generate  cmd.GenCmd       `cmd:"" aliases:"gen" help:"Generate pack data and thumbtails"`
+	List      cmd.ListCmd      `cmd:"" aliases:"ls" help:"list resources in a .dungeondraft_pack file"`
+	Edit      cmd.EditCmd      `cmd:"" help:"Edit pack info, tags, and tag sets"`
+	Release   cmd.ReleaseCmd   `cmd:"" help:"Bump the pack version, write a changelog entry against the previous release, and pack"`
+	Map       cmd.MapCmd       `cmd:"" help:"Inspect .dungeondraft_map files and the pack assets they use"`
+	BundleMap cmd.BundleMapCmd `cmd:"" help:"Copy the assets a map uses from installed packs into a new pack"`
+	Tags      cmd.TagsCmd      `cmd:"" help:"Export, import, and maintain the tags of a pack"`
 }
 
 func main() {
@@ -58,5 +62,6 @@ func main() {
 	})
 
 	err := cliCtx.Run(ctx)
+	ctx.Close()
 	cliCtx.FatalIfErrorf(err)
 }
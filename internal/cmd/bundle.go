@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddmap"
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+)
+
+type BundleMapCmd struct {
+	MapPath string   `arg:"" type:"existingfile" help:"the .dungeondraft_map to bundle the assets of"`
+	Packs   []string `required:"" type:"path" help:"folders of installed .dungeondraft_pack files, or pack files, to find the map assets in"`
+	Out     string   `type:"path" default:"." help:"the folder to create the bundle in"`
+
+	Name    string `short:"N" help:"name of the bundle package, defaults to the map file name"`
+	Author  string `short:"A" default:"bundle-map" help:"bundle package author"`
+	Version string `short:"V" default:"1" help:"bundle package version"`
+	ID      string `short:"I" help:"id of the bundle package, defaults to a randomly generated id"`
+
+	Packed     bool `help:"write a .dungeondraft_pack instead of a package folder"`
+	RewriteMap bool `help:"rewrite the map to use the bundle, keeping a .bak copy"`
+}
+
+// backupFile moves a file to the first free <path>.bak, <path>.bak2, ...
+func backupFile(path string) (string, error) {
+	backupPath := path + ".bak"
+	for n := 2; utils.FileExists(backupPath); n++ {
+		backupPath = fmt.Sprintf("%s.bak%d", path, n)
+	}
+	return backupPath, os.Rename(path, backupPath)
+}
+
+func (bmc *BundleMapCmd) Run(ctx *Context) error {
+	l := log.WithField("map", bmc.MapPath)
+
+	m, err := ddmap.LoadMap(bmc.MapPath)
+	if err != nil {
+		l.WithError(err).Error("failed to load map")
+		return err
+	}
+
+	packFiles, err := collectFiles(bmc.Packs, ".dungeondraft_pack")
+	if err != nil {
+		return err
+	}
+
+	// only keep the packs the map uses open
+	packIDs := m.PackIDs()
+	var sources []*ddpackage.Package
+	defer func() {
+		for _, src := range sources {
+			src.Close()
+		}
+	}()
+	for _, packFile := range packFiles {
+		pl := log.WithField("path", packFile)
+		src := ddpackage.NewPackage(pl)
+		err := src.LoadFromPackedPath(packFile, nil)
+		if err != nil {
+			pl.WithError(err).Warn("skipping package that failed to load")
+			continue
+		}
+		if !slices.Contains(packIDs, src.ID()) {
+			src.Close()
+			continue
+		}
+		err = src.LoadTags()
+		if err != nil {
+			src.Close()
+			pl.WithError(err).Error("failed to load tags")
+			return err
+		}
+		sources = append(sources, src)
+	}
+
+	name := bmc.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(bmc.MapPath), filepath.Ext(bmc.MapPath))
+	}
+
+	outDirPath, err := filepath.Abs(bmc.Out)
+	if err != nil {
+		return errors.Join(err, errors.New("could not get absolute path for out folder"))
+	}
+	bundlePath := filepath.Join(outDirPath, name)
+	if bmc.Packed {
+		bundlePath, err = os.MkdirTemp("", "dungeondraft-bundle-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(bundlePath)
+	} else if utils.DirExists(bundlePath) {
+		return fmt.Errorf("bundle folder %s already exists", bundlePath)
+	}
+
+	result, err := ddmap.Bundle(l, m, sources, ddmap.BundleOptions{
+		Path:    bundlePath,
+		Name:    name,
+		Author:  bmc.Author,
+		Version: bmc.Version,
+		ID:      bmc.ID,
+	})
+	if err != nil {
+		l.WithError(err).Error("failed to bundle map assets")
+		return err
+	}
+
+	outPath := bundlePath
+	if bmc.Packed {
+		pkg := ddpackage.NewPackage(l.WithField("bundlePath", bundlePath))
+		err = pkg.LoadUnpackedFromFolder(bundlePath)
+		if err != nil {
+			return err
+		}
+		defer pkg.Close()
+		errs := pkg.BuildFileList()
+		if len(errs) != 0 {
+			return errors.Join(errs...)
+		}
+		err = pkg.PackPackage(outDirPath, ddpackage.PackOptions{})
+		if err != nil {
+			l.WithError(err).Error("failed to pack bundle")
+			return err
+		}
+		outPath = filepath.Join(outDirPath, name+".dungeondraft_pack")
+	}
+
+	fmt.Printf("bundled %d assets from %d packs into %s (%s)\n", result.Resources, len(sources), outPath, result.ID)
+	if len(result.Unresolved) > 0 {
+		fmt.Println("Unresolved references:")
+		for _, ref := range result.Unresolved {
+			fmt.Printf("  %s\n", ref)
+		}
+	}
+
+	if bmc.RewriteMap {
+		changed, err := m.UseBundle(result)
+		if err != nil {
+			l.WithError(err).Error("failed to rewrite map")
+			return err
+		}
+		backupPath, err := backupFile(m.Path)
+		if err != nil {
+			l.WithError(err).Error("failed to back up map")
+			return err
+		}
+		err = m.WriteFile(m.Path)
+		if err != nil {
+			l.WithError(err).Error("failed to write rewritten map")
+			return err
+		}
+		fmt.Printf("rewrote %d references in %s, original kept as %s\n", changed, filepath.Base(m.Path), filepath.Base(backupPath))
+	}
+
+	return nil
+}
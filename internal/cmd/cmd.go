@@ -27,28 +27,56 @@ func (ctx *Context) LoadPkg(path string) error {
 		"inputPath": ctx.InputPath,
 	})
 
-	ctx.Pkg = ddpackage.NewPackage(ctx.Log)
-	if utils.DirExists(ctx.InputPath) {
-		err := ctx.Pkg.LoadUnpackedFromFolder(ctx.InputPath)
+	pkg, err := loadPackage(ctx.Log, ctx.InputPath)
+	if err != nil {
+		return err
+	}
+	ctx.Pkg = pkg
+	return nil
+}
+
+// LoadEditPkg loads a package that changes are saved to unless dryRun is set. zipped package folders
+// are only allowed for a dry run, they are extracted to a temporary folder and changes would be lost
+func (ctx *Context) LoadEditPkg(path string, dryRun bool) error {
+	if !dryRun && ddpackage.IsZipPath(path) {
+		return errors.Join(ddpackage.ErrPackageZipped, fmt.Errorf("%s is a zip, unzip it to make changes", path))
+	}
+	return ctx.LoadPkg(path)
+}
+
+// loadPackage loads a packed package, or an unpacked package folder or zip with its file list built
+func loadPackage(l log.FieldLogger, packPath string) (*ddpackage.Package, error) {
+	pkg := ddpackage.NewPackage(l)
+	if utils.DirExists(packPath) || ddpackage.IsZipPath(packPath) {
+		err := pkg.LoadUnpackedFromFolder(packPath)
 		if err != nil {
-			ctx.Log.WithError(err).Error("failed to load package")
-			return err
+			l.WithError(err).Error("failed to load package")
+			pkg.Close()
+			return nil, err
 		}
-		errs := ctx.Pkg.BuildFileList()
+		errs := pkg.BuildFileList()
 		if len(errs) != 0 {
 			for _, err := range errs {
-				ctx.Log.WithField("task", "building file list").Errorf("error : %s", err.Error())
+				l.WithField("task", "building file list").Errorf("error : %s", err.Error())
 			}
-			return errors.Join(errs...)
+			pkg.Close()
+			return nil, errors.Join(errs...)
 		}
 	} else {
-		err := ctx.Pkg.LoadFromPackedPath(ctx.InputPath, nil)
+		err := pkg.LoadFromPackedPath(packPath, nil)
 		if err != nil {
-			ctx.Log.WithError(err).Error("failed to load package")
-			return err
+			l.WithError(err).Error("failed to load package")
+			return nil, err
 		}
 	}
-	return nil
+	return pkg, nil
+}
+
+// Close releases the loaded package, if any
+func (ctx *Context) Close() {
+	if ctx.Pkg != nil {
+		ctx.Pkg.Close()
+	}
 }
 
 func (ctx *Context) LoadTags() error {
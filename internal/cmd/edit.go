@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"slices"
 
 	"github.com/ryex/dungeondraft-gopackager/internal/utils"
@@ -24,8 +26,8 @@ type EditPackCmd struct {
 
 	AllowThirdParty *bool `short:"M" help:" set the 'allow_3rd_party_mapping_software_to_read' key. package will be incompatible with Dungeondraft v1.0.3.2" default:"true"`
 
-	AddKeywords    []string `short:"AK" help:"comma separated keywords to add"`
-	RemoveKeywords []string `short:"RK" help:"comma separated keywords to remove"`
+	AddKeywords    []string `short:"K" help:"comma separated keywords to add"`
+	RemoveKeywords []string `help:"comma separated keywords to remove"`
 
 	MinRedness    *float64 `short:"R" help:"enable custom colors and set the minimum redness value" default:"0.1"`
 	MinSaturation *float64 `short:"S" help:"enable custom colors and set the minimum saturation value" default:"0"`
@@ -33,7 +35,7 @@ type EditPackCmd struct {
 }
 
 func (epc *EditPackCmd) Run(ctx *Context) error {
-	err := ctx.LoadPkg(epc.InputPath)
+	err := ctx.LoadEditPkg(epc.InputPath, false)
 	if err != nil {
 		return err
 	}
@@ -94,14 +96,37 @@ func (epc *EditPackCmd) Run(ctx *Context) error {
 }
 
 type EditTagsCmd struct {
+	Resources EditTagsResourcesCmd `cmd:"" default:"withargs" help:"add or remove tags from resources, the default when no subcommand is given"`
+	Rename    EditTagsRenameCmd    `cmd:"" help:"rename a tag, keeping its resources and the sets it is in"`
+	Merge     EditTagsMergeCmd     `cmd:"" help:"merge tags into one tag, updating every set they are in"`
+	Normalize EditTagsNormalizeCmd `cmd:"" help:"merge tags that only differ by whitespace, case, or optionally being plural"`
+}
+
+type EditTagsResourcesCmd struct {
 	InputPath string   `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
 	Command   string   `arg:"" enum:"add,remove"`
-	Tags      []string `short:"t" required:""`
-	Globs     []string `arg:"" help:"patterns or paths to work with"`
+	Tags      []string `short:"t" help:"comma separated tags to add or remove"`
+	Query     string   `short:"q" help:"only work with resources that match a tag query, e.g. 'Furniture AND NOT set:Interior'"`
+	Globs     []string `arg:"" optional:"" help:"patterns or paths to work with, all taggable resources if only a query is given"`
 }
 
-func (etc *EditTagsCmd) Run(ctx *Context) error {
-	err := ctx.LoadPkg(etc.InputPath)
+func (etc *EditTagsResourcesCmd) Run(ctx *Context) error {
+	// checked here, with required:"" kong requires it for every edit tags subcommand
+	if len(etc.Tags) == 0 {
+		return errors.New("missing flags: --tags=TAGS,...")
+	}
+	if len(etc.Globs) == 0 && etc.Query == "" {
+		return errors.New("expected globs or a --query")
+	}
+	var query *structures.TagQuery
+	if etc.Query != "" {
+		var err error
+		query, err = structures.ParseTagQuery(etc.Query)
+		if err != nil {
+			return err
+		}
+	}
+	err := ctx.LoadEditPkg(etc.InputPath, false)
 	if err != nil {
 		return err
 	}
@@ -110,11 +135,19 @@ func (etc *EditTagsCmd) Run(ctx *Context) error {
 		return err
 	}
 
-	fileList, err := ctx.Pkg.FileList().Glob(func(fi *structures.FileInfo) bool {
-		return fi.IsObject()
-	}, etc.Globs...)
-	if err != nil {
-		return err
+	fileList := ctx.Pkg.FileList().Filter(func(fi *structures.FileInfo) bool {
+		return fi.IsTaggable()
+	})
+	if len(etc.Globs) > 0 {
+		fileList, err = fileList.Glob(nil, etc.Globs...)
+		if err != nil {
+			return err
+		}
+	}
+	if query != nil {
+		fileList = fileList.Filter(func(fi *structures.FileInfo) bool {
+			return query.Match(ctx.Pkg.Tags(), fi.CalcRelPath())
+		})
 	}
 
 	resPaths := slices.Collect(utils.Map(slices.Values(fileList), func(fi *structures.FileInfo) string {
@@ -142,6 +175,103 @@ func (etc *EditTagsCmd) Run(ctx *Context) error {
 	return nil
 }
 
+type EditTagsRenameCmd struct {
+	InputPath string `arg:"" type:"path" help:"the resource directory to work with"`
+	From      string `arg:"" help:"the tag to rename"`
+	To        string `arg:"" help:"the new name of the tag"`
+}
+
+func (etrc *EditTagsRenameCmd) Run(ctx *Context) error {
+	err := ctx.LoadEditPkg(etrc.InputPath, false)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	if !ctx.Pkg.Tags().TagExists(etrc.From) {
+		return fmt.Errorf("no tag named '%s'", etrc.From)
+	}
+	if ctx.Pkg.Tags().TagExists(etrc.To) {
+		return fmt.Errorf("tag '%s' already exists, use merge to combine the tags", etrc.To)
+	}
+	ctx.Pkg.Tags().RenameTag(etrc.From, etrc.To)
+
+	err = ctx.Pkg.SaveUnpackedTags()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type EditTagsMergeCmd struct {
+	InputPath string   `arg:"" type:"path" help:"the resource directory to work with"`
+	Into      string   `arg:"" help:"the tag to merge into, created if it does not exist"`
+	From      []string `arg:"" help:"the tags to merge, they are removed"`
+}
+
+func (etmc *EditTagsMergeCmd) Run(ctx *Context) error {
+	err := ctx.LoadEditPkg(etmc.InputPath, false)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range etmc.From {
+		if !ctx.Pkg.Tags().TagExists(tag) {
+			return fmt.Errorf("no tag named '%s'", tag)
+		}
+	}
+	ctx.Pkg.Tags().MergeTags(etmc.Into, etmc.From...)
+
+	err = ctx.Pkg.SaveUnpackedTags()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+type EditTagsNormalizeCmd struct {
+	InputPath string `arg:"" type:"path" help:"the resource directory to work with"`
+
+	Singularize bool `help:"also merge plural tags into their singular if it is a tag, e.g. Chairs into Chair"`
+	DryRun      bool `help:"print the merges without saving them"`
+}
+
+func (etnc *EditTagsNormalizeCmd) Run(ctx *Context) error {
+	err := ctx.LoadEditPkg(etnc.InputPath, etnc.DryRun)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	renamed := ctx.Pkg.Tags().NormalizeTags(etnc.Singularize)
+	if len(renamed) == 0 {
+		fmt.Println("tags are already normalized")
+		return nil
+	}
+	for _, tag := range slices.Sorted(maps.Keys(renamed)) {
+		fmt.Printf("%q -> %q\n", tag, renamed[tag])
+	}
+	if etnc.DryRun {
+		return nil
+	}
+
+	err = ctx.Pkg.SaveUnpackedTags()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 type EditSetsCmd struct {
 	InputPath string   `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
 	TagSet    string   `arg:"" help:"the tag Set to work with"`
@@ -150,7 +280,7 @@ type EditSetsCmd struct {
 }
 
 func (esc *EditSetsCmd) Run(ctx *Context) error {
-	err := ctx.LoadPkg(esc.InputPath)
+	err := ctx.LoadEditPkg(esc.InputPath, false)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 
 	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
@@ -13,6 +14,7 @@ import (
 type GenCmd struct {
 	Pack       GenPackCmd `cmd:"" help:"Create a pack.json and populate it"`
 	Thumbnails GenTumbCmd `cmd:"" aliases:"thumb" help:"Generate or regenerate thumbnails for the eventual packed resources"`
+	Tags       GenTagsCmd `cmd:"" help:"Generate tags for the resources of a package folder"`
 }
 
 type GenPackCmd struct {
@@ -137,3 +139,102 @@ func (gtc *GenTumbCmd) Run(ctx *Context) error {
 
 	return nil
 }
+
+type GenTagsCmd struct {
+	InputPath string `arg:"" type:"existingdir" help:"the package folder path"`
+
+	GlobalSet string `help:"add every generated tag to this tag set"`
+
+	PrefixSets     bool   `default:"true" negatable:"" help:"build tag sets from folder name prefixes, e.g. '{Furniture} Chairs' puts the Chairs tag in the Furniture set"`
+	PrefixStart    string `default:"{" help:"start delimiter of a tag set prefix"`
+	PrefixEnd      string `default:"}" help:"end delimiter of a tag set prefix"`
+	SplitMode      bool   `help:"split folder names on a single separator instead of start and end delimiters, e.g. 'Furniture|Chairs'"`
+	SplitSeparator string `default:"|" help:"separator between tag sets and the tag in split mode"`
+	StripPrefix    bool   `default:"true" negatable:"" help:"strip tag set prefixes from the tags"`
+	ExtraPrefix    string `help:"a prefix to strip from folder names, e.g. a numbering scheme"`
+
+	FileNames bool              `help:"make a tag of each word in the file names, split on separators and camelCase"`
+	StopWords []string          `help:"comma separated file name words that are not made into tags" default:"a,an,and,the,of,with,for,in,on,to"`
+	Synonym   map[string]string `help:"map a file name word to a tag, e.g. --synonym chr=Chair, an empty tag drops the word"`
+	Case      string            `enum:"title,lower,upper" default:"title" help:"capitalisation of tags made from file names"`
+
+	Colors   bool   `help:"tag objects with the names of their dominant colors"`
+	Palette  string `type:"existingfile" help:"json file of color tag names to hex colors, e.g. {\"Red\": \"#b0302a\"}, defaults to a built in palette"`
+	ColorSet string `default:"Colors" help:"the tag set color tags are added to"`
+
+	DryRun  bool `help:"print the tag and set changes without saving them"`
+	Replace bool `help:"remove the generated tags from every resource first, so they end up only on the resources generated for them. other tags are kept"`
+
+	Progress bool `default:"true" negatable:"" help:"show progressbar"`
+}
+
+func (gtc *GenTagsCmd) Run(ctx *Context) error {
+	err := ctx.LoadPkg(gtc.InputPath)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	var palette ddpackage.ColorPalette
+	if gtc.Palette != "" {
+		palette, err = ddpackage.LoadColorPalette(gtc.Palette)
+		if err != nil {
+			ctx.Log.WithError(err).WithField("palette", gtc.Palette).Error("failed to load color palette")
+			return err
+		}
+	}
+
+	delimiter := [2]string{gtc.PrefixStart, gtc.PrefixEnd}
+	if gtc.SplitMode {
+		delimiter = [2]string{gtc.SplitSeparator, ""}
+	}
+
+	generator := ddpackage.NewGenerateTags(&ddpackage.GenerateTagsOptions{
+		BuildGlobalTagSet:      gtc.GlobalSet != "",
+		GlobalTagSet:           gtc.GlobalSet,
+		BuildTagSetsFromPrefix: gtc.PrefixSets,
+		PrefixSplitMode:        gtc.SplitMode,
+		TagSetPrefrixDelimiter: delimiter,
+		StripTagSetPrefix:      gtc.StripPrefix,
+		StripExtraPrefix:       gtc.ExtraPrefix,
+		FromFileNames:          gtc.FileNames,
+		FileNameStopWords:      gtc.StopWords,
+		FileNameSynonyms:       gtc.Synonym,
+		FileNameCase:           ddpackage.TagCase(gtc.Case),
+		FromColors:             gtc.Colors,
+		ColorPalette:           palette,
+		ColorTagSet:            gtc.ColorSet,
+	})
+
+	var tags *structures.PackageTags
+	var diff *structures.TagsDiff
+	if gtc.Progress {
+		total := len(ctx.Pkg.FileList())
+		bar := progressbar.Default(int64(total), "Generating Tags ...")
+		tags, diff = ctx.Pkg.PreviewGenerateTagsProgress(generator, gtc.Replace, func(p float64) {
+			bar.Set(int(p * float64(total)))
+		})
+		bar.Finish()
+	} else {
+		tags, diff = ctx.Pkg.PreviewGenerateTags(generator, gtc.Replace)
+	}
+
+	if gtc.DryRun {
+		if diff.Empty() {
+			fmt.Println("no tags to change")
+			return nil
+		}
+		printTagsDiff(diff)
+		return nil
+	}
+
+	err = ctx.Pkg.ApplyGeneratedTags(tags)
+	if err != nil {
+		ctx.Log.WithError(err).Error("failed to save tags")
+		return err
+	}
+	return nil
+}
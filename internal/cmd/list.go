@@ -28,8 +28,9 @@ type ListFilesCmd struct {
 	Thumbnails bool     `short:"T" default:"false" negatable:"" help:"list thumbnail files"`
 	Data       bool     `short:"D" default:"false" negatable:"" help:"list Data files (tags, and wall/terrain metadata )"`
 	Type       string   `enum:"tree,list" default:"list" help:"print the files in a resource path tree or a list as packed"`
-	InputPath  string   `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
+	InputPath  string   `arg:"" type:"path" help:"the .dungeondraft_pack file, resource directory, or .zip of a resource directory to work with"`
 	ByTag      []string `short:"t" help:"List objects that match these tags (comma separated)"`
+	Query      string   `short:"q" help:"List objects that match a tag query, e.g. 'Furniture AND (Wood OR Metal) AND NOT Broken', terms can also be set:<set>, path:<glob>, and is:untagged"`
 	Globs      []string `arg:"" optional:"" help:"optional glob patterns to filter the output by"`
 }
 
@@ -55,11 +56,21 @@ func (lsf *ListFilesCmd) Run(ctx *Context) error {
 		return true
 	}
 
+	var query *structures.TagQuery
+	if lsf.Query != "" {
+		query, err = structures.ParseTagQuery(lsf.Query)
+		if err != nil {
+			return err
+		}
+	}
+
 	fileList := ctx.Pkg.FileList()
-	if len(lsf.ByTag) > 0 {
+	if len(lsf.ByTag) > 0 || query != nil {
 		ctx.Pkg.LoadTags()
+	}
+	if len(lsf.ByTag) > 0 {
 		fileList = fileList.Filter(func(fi *structures.FileInfo) bool {
-			if !fi.IsObject() {
+			if !fi.IsTaggable() {
 				return false
 			}
 			tags := ctx.Pkg.Tags().TagsFor(fi.CalcRelPath())
@@ -68,6 +79,11 @@ func (lsf *ListFilesCmd) Run(ctx *Context) error {
 			})
 		})
 	}
+	if query != nil {
+		fileList = fileList.Filter(func(fi *structures.FileInfo) bool {
+			return fi.IsTaggable() && query.Match(ctx.Pkg.Tags(), fi.CalcRelPath())
+		})
+	}
 	if len(lsf.Globs) > 0 {
 		var err error
 		fileList, err = fileList.Glob(filterFunc, lsf.Globs...)
@@ -143,7 +159,7 @@ func (lsf *ListFilesCmd) printTree(
 }
 
 type ListTagsCmd struct {
-	InputPath    string   `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
+	InputPath    string   `arg:"" type:"path" help:"the .dungeondraft_pack file, resource directory, or .zip of a resource directory to work with"`
 	GlobPatterns []string `arg:"" optional:"" help:"glob patterns to match against paths relative to package root (paths should not stor with a dot (./) and must use slash separation even on windows (a/b))"`
 }
 
@@ -164,7 +180,7 @@ func (ls *ListTagsCmd) printTags(l logrus.FieldLogger, pkg *ddpackage.Package) e
 	if len(ls.GlobPatterns) < 1 {
 		tags = pkg.Tags().AllTags()
 	} else {
-		files, err := pkg.FileList().Glob(func(fi *structures.FileInfo) bool { return fi.IsObject() }, ls.GlobPatterns...)
+		files, err := pkg.FileList().Glob(func(fi *structures.FileInfo) bool { return fi.IsTaggable() }, ls.GlobPatterns...)
 		if err != nil {
 			l.WithError(err).Error("failed to glob file list")
 			return err
@@ -178,7 +194,7 @@ func (ls *ListTagsCmd) printTags(l logrus.FieldLogger, pkg *ddpackage.Package) e
 }
 
 type ListSetsCmd struct {
-	InputPath string `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
+	InputPath string `arg:"" type:"path" help:"the .dungeondraft_pack file, resource directory, or .zip of a resource directory to work with"`
 	TagSet    string `arg:"" optional:"" help:"an optional tag set to list tags for"`
 }
 
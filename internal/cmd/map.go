@@ -0,0 +1,295 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddmap"
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+)
+
+type MapCmd struct {
+	Info    MapInfoCmd    `cmd:"" help:"summarise the levels, asset counts, and referenced packs of maps"`
+	Usage   MapUsageCmd   `cmd:"" help:"report which assets of a pack are used by a set of maps"`
+	Migrate MapMigrateCmd `cmd:"" help:"rewrite asset references in maps after assets are moved, renamed, or a pack id changes"`
+}
+
+type MapInfoCmd struct {
+	Maps []string `arg:"" type:"path" help:"the .dungeondraft_map files, or folders containing them, to summarise"`
+}
+
+type MapUsageCmd struct {
+	InputPath string   `arg:"" type:"path" help:"the .dungeondraft_pack file, resource directory, or .zip of a resource directory to check"`
+	Maps      []string `arg:"" type:"path" help:"the .dungeondraft_map files, or folders containing them, to scan"`
+
+	NoUnused bool `help:"don't list the assets no map uses"`
+}
+
+// loadMaps expands folders in the paths and loads the .dungeondraft_map files
+func loadMaps(paths []string) ([]*ddmap.Map, error) {
+	mapFiles, err := collectFiles(paths, ".dungeondraft_map")
+	if err != nil {
+		return nil, err
+	}
+	if len(mapFiles) == 0 {
+		return nil, errors.New("no maps to scan")
+	}
+	maps := make([]*ddmap.Map, 0, len(mapFiles))
+	for _, mapFile := range mapFiles {
+		m, err := ddmap.LoadMap(mapFile)
+		if err != nil {
+			log.WithError(err).WithField("map", mapFile).Error("failed to load map")
+			return nil, err
+		}
+		maps = append(maps, m)
+	}
+	return maps, nil
+}
+
+func (mic *MapInfoCmd) Run(ctx *Context) error {
+	maps, err := loadMaps(mic.Maps)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range maps {
+		if i > 0 {
+			fmt.Println()
+		}
+		printMapInfo(m)
+	}
+	return nil
+}
+
+func printMapInfo(m *ddmap.Map) {
+	fmt.Println(filepath.Base(m.Path))
+	if m.Header != nil {
+		fmt.Printf("  created with build %s\n", m.Header.CreationBuild)
+	}
+	if m.World == nil {
+		fmt.Println("  no world data")
+		return
+	}
+	fmt.Printf("  %dx%d cells, %d levels\n\n", m.World.Width, m.World.Height, len(m.World.Levels))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  LEVEL\tLABEL\tOBJECTS\tWALLS\tPATHS\tLIGHTS")
+	for _, key := range m.World.LevelKeys() {
+		level := m.World.Levels[key]
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%d\t%d\t%d\n",
+			key, level.Label, len(level.Objects), len(level.Walls), len(level.Paths), len(level.Lights))
+	}
+	w.Flush()
+	fmt.Println()
+
+	// group the references by pack, including manifest packs with no references
+	type packRefs struct {
+		assets int
+		uses   int
+	}
+	byPack := make(map[string]*packRefs)
+	names := make(map[string]string)
+	if m.Header != nil {
+		for _, mp := range m.Header.AssetManifest {
+			byPack[mp.ID] = &packRefs{}
+			names[mp.ID] = mp.Name
+		}
+	}
+	refs := m.References()
+	uses := 0
+	for ref, count := range refs {
+		id := ddmap.RefPackID(ref)
+		if byPack[id] == nil {
+			byPack[id] = &packRefs{}
+		}
+		byPack[id].assets += 1
+		byPack[id].uses += count
+		uses += count
+	}
+
+	fmt.Printf("  %d pack assets, %d references, from %d packs\n", len(refs), uses, len(byPack))
+	if len(byPack) == 0 {
+		return
+	}
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PACK\tNAME\tASSETS\tUSES")
+	for _, id := range slices.Sorted(maps.Keys(byPack)) {
+		name, ok := names[id]
+		if !ok {
+			name = "(not in manifest)"
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%d\n", id, name, byPack[id].assets, byPack[id].uses)
+	}
+	w.Flush()
+}
+
+func (muc *MapUsageCmd) Run(ctx *Context) error {
+	err := ctx.LoadPkg(muc.InputPath)
+	if err != nil {
+		return err
+	}
+
+	maps, err := loadMaps(muc.Maps)
+	if err != nil {
+		return err
+	}
+
+	report := ddmap.AnalyzeUsage(ctx.Pkg, maps)
+
+	fmt.Printf("%s (%s) in %d maps: %d assets used, %d unused, %d missing\n\n",
+		report.PackName, report.PackID, len(maps), len(report.Used), len(report.Unused), len(report.Missing))
+
+	if len(report.Used) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "USES\tMAPS\tASSET")
+		for _, au := range report.Used {
+			fmt.Fprintf(w, "%d\t%d\t%s\n", au.Count, len(au.Maps), au.ResPath)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	if len(report.Unused) > 0 && !muc.NoUnused {
+		fmt.Println("Unused assets:")
+		for _, resPath := range report.Unused {
+			fmt.Printf("  %s\n", resPath)
+		}
+		fmt.Println()
+	}
+
+	if len(report.Missing) > 0 {
+		fmt.Println("Missing assets:")
+		for _, au := range report.Missing {
+			fmt.Printf("  %s (%d uses in %s)\n", au.ResPath, au.Count, strings.Join(baseNames(au.Maps), ", "))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("Map dependencies:")
+	for _, dep := range report.Dependencies {
+		fmt.Printf("  %s: %s\n", filepath.Base(dep.Map), strings.Join(dep.PackIDs, ", "))
+	}
+
+	return nil
+}
+
+func baseNames(paths []string) []string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = filepath.Base(path)
+	}
+	return names
+}
+
+type MapMigrateCmd struct {
+	Maps []string `arg:"" type:"path" help:"the .dungeondraft_map files, or folders containing them, to migrate"`
+
+	From string            `type:"path" help:"the old version of the pack (.dungeondraft_pack, folder, or .zip), moved assets are found by diffing against --to"`
+	To   string            `type:"path" help:"the new version of the pack (.dungeondraft_pack, folder, or .zip)"`
+	Csv  string            `type:"existingfile" help:"csv of old,new rows of res://packs/ paths or pack ids"`
+	ID   map[string]string `name:"id" help:"map an old pack id to a new one (old=new)"`
+
+	NoBackup bool `help:"don't keep a .bak copy of each changed map"`
+	DryRun   bool `help:"report the changes without writing any files"`
+}
+
+// migration combines the mapping sources of the command
+func (mmc *MapMigrateCmd) migration() (*ddmap.Migration, error) {
+	mig := ddmap.NewMigration()
+
+	if (mmc.From == "") != (mmc.To == "") {
+		return nil, errors.New("--from and --to must be used together")
+	}
+	if mmc.From != "" {
+		oldPkg, err := loadPackage(log.WithField("path", mmc.From), mmc.From)
+		if err != nil {
+			return nil, err
+		}
+		defer oldPkg.Close()
+		newPkg, err := loadPackage(log.WithField("path", mmc.To), mmc.To)
+		if err != nil {
+			return nil, err
+		}
+		defer newPkg.Close()
+		diff, err := ddpackage.DiffPackages(oldPkg, newPkg)
+		if err != nil {
+			return nil, errors.Join(err, errors.New("failed to compare packs"))
+		}
+		diffMig := ddmap.MigrationFromDiff(oldPkg, newPkg, diff)
+		maps.Copy(mig.Paths, diffMig.Paths)
+		maps.Copy(mig.PackIDs, diffMig.PackIDs)
+	}
+
+	if mmc.Csv != "" {
+		f, err := os.Open(mmc.Csv)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		csvMig, err := ddmap.ReadMigrationCSV(f)
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to read %s", mmc.Csv))
+		}
+		maps.Copy(mig.Paths, csvMig.Paths)
+		maps.Copy(mig.PackIDs, csvMig.PackIDs)
+	}
+
+	maps.Copy(mig.PackIDs, mmc.ID)
+
+	if mig.Empty() {
+		return nil, errors.New("nothing to migrate, provide --from and --to, --csv, or --id")
+	}
+	return mig, nil
+}
+
+func (mmc *MapMigrateCmd) Run(ctx *Context) error {
+	mig, err := mmc.migration()
+	if err != nil {
+		return err
+	}
+
+	mapList, err := loadMaps(mmc.Maps)
+	if err != nil {
+		return err
+	}
+
+	total := 0
+	for _, m := range mapList {
+		l := log.WithField("map", m.Path)
+		changed, err := m.Migrate(mig)
+		if err != nil {
+			l.WithError(err).Error("failed to migrate map")
+			return err
+		}
+		fmt.Printf("%s: %d references changed\n", filepath.Base(m.Path), changed)
+		total += changed
+		if changed == 0 || mmc.DryRun {
+			continue
+		}
+
+		if !mmc.NoBackup {
+			backupPath, err := backupFile(m.Path)
+			if err != nil {
+				l.WithError(err).Error("failed to back up map")
+				return err
+			}
+			l.WithField("backupPath", backupPath).Info("backed up map")
+		}
+		err = m.WriteFile(m.Path)
+		if err != nil {
+			l.WithError(err).Error("failed to write migrated map")
+			return err
+		}
+	}
+
+	fmt.Printf("%d references changed in %d maps\n", total, len(mapList))
+	return nil
+}
@@ -7,16 +7,47 @@ import (
 	"github.com/schollz/progressbar/v3"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddimage"
 	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
 )
 
 type PackCmd struct {
-	InputPath       string `arg:"" type:"path" help:"the package folder path"`
+	InputPath       string `arg:"" type:"path" help:"the package folder path, or a .zip containing the package folder"`
 	DestinationPath string `arg:"" type:"path" help:"the destination folder path to place the packaged .dungeondraft_pack"`
 
 	Overwrite  bool `short:"O" help:"overwrite output files at destination"`
 	Thumbnails bool `short:"T" help:"generate thumbnails"`
+	ApplyRules bool `help:"tag resources with the rules in tag_rules.jsonc before packing"`
 	Progress   bool `default:"true" negatable:"" help:"show progressbar"`
+
+	SvgFlags `embed:""`
+}
+
+type SvgFlags struct {
+	SvgSize map[string]int     `placeholder:"CATEGORY=PIXELS" help:"rasterize .svg textures in a category (the folder under textures/, or 'default' for all others) with their longest side at this many pixels"`
+	SvgDPI  map[string]float64 `name:"svg-dpi" placeholder:"CATEGORY=DPI" help:"rasterize .svg textures in a category (the folder under textures/, or 'default' for all others) at this DPI, the viewBox is taken to be 96 DPI"`
+}
+
+// SvgSizes converts the flags to the per category sizes used in PackOptions
+func (sf *SvgFlags) SvgSizes() map[string]ddimage.SvgSize {
+	sizes := make(map[string]ddimage.SvgSize)
+	key := func(category string) string {
+		if category == "default" {
+			return ""
+		}
+		return category
+	}
+	for category, dpi := range sf.SvgDPI {
+		size := sizes[key(category)]
+		size.DPI = dpi
+		sizes[key(category)] = size
+	}
+	for category, px := range sf.SvgSize {
+		size := sizes[key(category)]
+		size.Size = px
+		sizes[key(category)] = size
+	}
+	return sizes
 }
 
 func (pc *PackCmd) Run(ctx *Context) error {
@@ -42,6 +73,14 @@ func (pc *PackCmd) Run(ctx *Context) error {
 		l.WithError(err).Error("could not load unpacked Package")
 		return err
 	}
+	defer pkg.Close()
+
+	options := ddpackage.PackOptions{
+		Overwrite: pc.Overwrite,
+		SvgSizes:  pc.SvgSizes(),
+	}
+	// set before building the file list so svg textures are rasterized at the right size
+	pkg.SetPackOptions(options)
 
 	errs := pkg.BuildFileList()
 	if len(errs) != 0 {
@@ -51,14 +90,32 @@ func (pc *PackCmd) Run(ctx *Context) error {
 		return errors.New("Failed to build file list")
 	}
 
+	if pc.ApplyRules {
+		err = pkg.LoadTags()
+		if err != nil {
+			l.WithError(err).Error("failed to load tags")
+			return err
+		}
+		rules, err := loadTagRules(l, pkg, "")
+		if err != nil {
+			return err
+		}
+		diff, err := pkg.ApplyTagRules(rules)
+		if err != nil {
+			l.WithError(err).Error("failed to save tags")
+			return err
+		}
+		l.WithField("changedTags", len(diff.ChangedTags())).Info("applied tag rules")
+	}
+
 	if pc.Progress {
 		total := int64(len(pkg.FileList()))
 		bar := progressbar.Default(total, "Packing ...")
-		err = pkg.PackPackageProgress(outDirPath, ddpackage.PackOptions{Overwrite: pc.Overwrite}, func(p float64) {
+		err = pkg.PackPackageProgress(outDirPath, options, func(p float64) {
 			bar.Set(int(p * float64(total)))
 		})
 	} else {
-		err = pkg.PackPackage(outDirPath, ddpackage.PackOptions{Overwrite: pc.Overwrite})
+		err = pkg.PackPackage(outDirPath, options)
 	}
 	if err != nil {
 		l.WithError(err).Error("packing failure")
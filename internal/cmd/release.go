@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+)
+
+type ReleaseCmd struct {
+	InputPath       string `arg:"" type:"path" help:"the package folder path"`
+	DestinationPath string `arg:"" type:"path" help:"the destination folder path to place the packaged .dungeondraft_pack"`
+
+	Bump      string `enum:"major,minor" default:"minor" help:"which part of the version to increment (major,minor)"`
+	Previous  string `type:"path" help:"the previously released .dungeondraft_pack to compare against, defaults to the existing pack at the destination"`
+	Changelog string `type:"path" help:"the Markdown changelog to prepend the release entry to, defaults to CHANGELOG.md in the package folder"`
+	DryRun    bool   `help:"print the changelog entry without changing any files"`
+	Progress  bool   `default:"true" negatable:"" help:"show progressbar"`
+
+	SvgFlags `embed:""`
+}
+
+func (rc *ReleaseCmd) Run(ctx *Context) error {
+	packDirPath, pathErr := filepath.Abs(rc.InputPath)
+	if pathErr != nil {
+		return errors.Join(pathErr, errors.New("could not get absolute path for pack folder"))
+	}
+
+	outDirPath, pathErr := filepath.Abs(rc.DestinationPath)
+	if pathErr != nil {
+		return errors.Join(pathErr, errors.New("could not get absolute path for dest folder"))
+	}
+
+	l := log.WithFields(log.Fields{
+		"path":           packDirPath,
+		"outPackagePath": outDirPath,
+	})
+
+	pkg := ddpackage.NewPackage(l)
+
+	err := pkg.LoadUnpackedFromFolder(packDirPath)
+	if err != nil {
+		l.WithError(err).Error("could not load unpacked Package")
+		return err
+	}
+
+	options := ddpackage.PackOptions{
+		Overwrite: true,
+		SvgSizes:  rc.SvgSizes(),
+	}
+	pkg.SetPackOptions(options)
+
+	errs := pkg.BuildFileList()
+	if len(errs) != 0 {
+		for _, err := range errs {
+			l.WithField("task", "build file list").Errorf("err: %s", err.Error())
+		}
+		return errors.New("Failed to build file list")
+	}
+
+	err = pkg.LoadTags()
+	if err != nil {
+		l.WithError(err).Error("failed to load tags")
+		return err
+	}
+
+	previousPath := rc.Previous
+	if previousPath == "" {
+		previousPath = filepath.Join(outDirPath, pkg.Name()+".dungeondraft_pack")
+	}
+
+	var diff *ddpackage.PackageDiff
+	if utils.FileExists(previousPath) {
+		pl := l.WithField("previousPath", previousPath)
+		prev := ddpackage.NewPackage(pl)
+		err = prev.LoadFromPackedPath(previousPath, nil)
+		if err != nil {
+			pl.WithError(err).Error("failed to load previous release")
+			return err
+		}
+		err = prev.LoadTags()
+		if err != nil {
+			prev.Close()
+			pl.WithError(err).Error("failed to load previous release tags")
+			return err
+		}
+		diff, err = ddpackage.DiffPackages(prev, pkg)
+		prev.Close()
+		if err != nil {
+			pl.WithError(err).Error("failed to compare with previous release")
+			return err
+		}
+	} else {
+		l.WithField("previousPath", previousPath).Warn("no previous release found, treating this as the initial release")
+	}
+
+	version, err := ddpackage.BumpVersion(pkg.Info().Version, rc.Bump == "major")
+	if err != nil {
+		l.WithError(err).Error("failed to bump version")
+		return err
+	}
+
+	entry := ddpackage.ChangelogEntry(version, time.Now(), diff)
+
+	if rc.DryRun {
+		fmt.Print(entry)
+		return nil
+	}
+
+	changelogPath := rc.Changelog
+	if changelogPath == "" {
+		changelogPath = filepath.Join(packDirPath, "CHANGELOG.md")
+	}
+
+	// keep the current pack.json and changelog to put back if the release fails,
+	// so a re-run does not bump the version or add an entry a second time
+	packJSONPath := filepath.Join(packDirPath, "pack.json")
+	packJSON, err := os.ReadFile(packJSONPath)
+	if err != nil {
+		l.WithError(err).Error("failed to read pack.json")
+		return err
+	}
+	changelog, err := os.ReadFile(changelogPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		l.WithError(err).Error("failed to read changelog")
+		return err
+	}
+	changelogExisted := err == nil
+	rollback := func() {
+		err := os.WriteFile(packJSONPath, packJSON, 0o644)
+		if err != nil {
+			l.WithError(err).Error("failed to restore pack.json")
+		}
+		if changelogExisted {
+			err = os.WriteFile(changelogPath, changelog, 0o644)
+		} else {
+			err = os.Remove(changelogPath)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			l.WithError(err).Error("failed to restore changelog")
+		}
+	}
+
+	pkg.SetVersion(version)
+	err = pkg.SaveUnpackedInfo()
+	if err != nil {
+		l.WithError(err).Error("failed to save pack.json")
+		rollback()
+		return err
+	}
+
+	err = ddpackage.PrependChangelogEntry(changelogPath, entry)
+	if err != nil {
+		l.WithError(err).Error("failed to write changelog")
+		rollback()
+		return err
+	}
+
+	if rc.Progress {
+		total := int64(len(pkg.FileList()))
+		bar := progressbar.Default(total, "Packing ...")
+		err = pkg.PackPackageProgress(outDirPath, options, func(p float64) {
+			bar.Set(int(p * float64(total)))
+		})
+	} else {
+		err = pkg.PackPackage(outDirPath, options)
+	}
+	if err != nil {
+		l.WithError(err).Error("packing failure, restoring pack.json and changelog")
+		rollback()
+		return err
+	}
+
+	fmt.Printf("released %s version %s\n", pkg.Name(), version)
+	return nil
+}
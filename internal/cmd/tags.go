@@ -0,0 +1,713 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/schollz/progressbar/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+)
+
+type TagsCmd struct {
+	Export     TagsExportCmd     `cmd:"" help:"write the tags of every taggable resource to a csv file"`
+	Import     TagsImportCmd     `cmd:"" help:"read the tags of resources from a csv file"`
+	ApplyRules TagsApplyRulesCmd `cmd:"" help:"tag resources with the rules in the tag rules file of the pack"`
+	Check      TagsCheckCmd      `cmd:"" help:"find tags of missing resources, empty tags and sets, and duplicate tags"`
+	Stats      TagsStatsCmd      `cmd:"" help:"report tag counts, untagged resources, and tag coverage"`
+	Transfer   TagsTransferCmd   `cmd:"" help:"copy the tags of one pack onto the matching resources of another"`
+	Merge      TagsMergeCmd      `cmd:"" help:"three-way merge of tags files, usable as a git merge driver"`
+	Library    TagsLibraryCmd    `cmd:"" help:"seed, check, and sync tag sets with a shared tag library"`
+}
+
+type TagsExportCmd struct {
+	InputPath string `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
+	Out       string `arg:"" optional:"" type:"path" help:"the csv file to write, defaults to stdout"`
+}
+
+func (tec *TagsExportCmd) Run(ctx *Context) error {
+	err := ctx.LoadPkg(tec.InputPath)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if tec.Out != "" {
+		f, err := os.Create(tec.Out)
+		if err != nil {
+			return errors.Join(err, fmt.Errorf("failed to create %s", tec.Out))
+		}
+		defer f.Close()
+		w = f
+	}
+
+	err = ctx.Pkg.WriteTagsCSV(w)
+	if err != nil {
+		ctx.Log.WithError(err).Error("failed to write tags csv")
+		return err
+	}
+	return nil
+}
+
+type TagsImportCmd struct {
+	InputPath string `arg:"" type:"path" help:"the resource directory to import into, a .dungeondraft_pack file or zip only with --dry-run"`
+	CsvPath   string `arg:"" type:"existingfile" help:"the csv file of path,tags,sets rows to import"`
+
+	Mode        string `enum:"merge,replace" default:"merge" help:"merge adds the tags in the csv, replace gives each listed resource exactly the listed tags"`
+	SkipUnknown bool   `help:"import even if the csv lists paths that are not taggable resources of the pack"`
+	DryRun      bool   `help:"report the changes without saving them"`
+}
+
+func (tic *TagsImportCmd) Run(ctx *Context) error {
+	if !tic.DryRun && !utils.DirExists(tic.InputPath) {
+		return errors.Join(
+			ddpackage.ErrPackageNotUnpacked,
+			fmt.Errorf("%s is not a resource directory, tags can only be imported into one", tic.InputPath),
+		)
+	}
+	err := ctx.LoadEditPkg(tic.InputPath, tic.DryRun)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(tic.CsvPath)
+	if err != nil {
+		return errors.Join(err, fmt.Errorf("failed to open %s", tic.CsvPath))
+	}
+	defer f.Close()
+
+	ti, err := ctx.Pkg.ReadTagsCSV(f, ddpackage.TagsImportMode(tic.Mode))
+	if err != nil {
+		ctx.Log.WithError(err).Error("failed to read tags csv")
+		return err
+	}
+
+	if len(ti.UnknownPaths) > 0 {
+		fmt.Println("Unknown paths, not taggable resources of the pack:")
+		for _, path := range ti.UnknownPaths {
+			fmt.Printf("  %s\n", path)
+		}
+		fmt.Println()
+	}
+	printTagsRemovals(ti.Diff)
+
+	tagged := 0
+	for _, resources := range ti.Diff.Tagged {
+		tagged += len(resources)
+	}
+	untagged := 0
+	for _, resources := range ti.Diff.Untagged {
+		untagged += len(resources)
+	}
+	fmt.Printf("%d tags added to resources, %d removed, %d new tags, %d tags removed\n",
+		tagged, untagged, len(ti.Diff.AddedTags), len(ti.Diff.RemovedTags))
+
+	if len(ti.UnknownPaths) > 0 && !tic.SkipUnknown {
+		return fmt.Errorf("%d unknown paths in %s, fix them or use --skip-unknown", len(ti.UnknownPaths), tic.CsvPath)
+	}
+	if tic.DryRun || ti.Diff.Empty() {
+		return nil
+	}
+
+	err = ctx.Pkg.ApplyTagsImport(ti)
+	if err != nil {
+		ctx.Log.WithError(err).Error("failed to save tags")
+		return err
+	}
+	return nil
+}
+
+// printTagsRemovals lists the tags and set memberships removed by a change to the tags
+func printTagsRemovals(diff *structures.TagsDiff) {
+	if len(diff.RemovedTags) > 0 {
+		fmt.Printf("Removed tags: %s\n\n", strings.Join(diff.RemovedTags, ", "))
+	}
+	if len(diff.Untagged) > 0 {
+		fmt.Println("Tags removed from resources:")
+		for _, tag := range diff.ChangedTags() {
+			resources, ok := diff.Untagged[tag]
+			if !ok {
+				continue
+			}
+			fmt.Printf("  %s:\n", tag)
+			for _, resource := range resources {
+				fmt.Printf("    - %s\n", resource)
+			}
+		}
+		fmt.Println()
+	}
+	if len(diff.SetRemoved) > 0 {
+		fmt.Println("Tags removed from sets:")
+		for _, set := range diff.ChangedSets() {
+			if tags, ok := diff.SetRemoved[set]; ok {
+				fmt.Printf("  %s: %s\n", set, strings.Join(tags, ", "))
+			}
+		}
+		fmt.Println()
+	}
+}
+
+type TagsApplyRulesCmd struct {
+	InputPath string `arg:"" type:"path" help:"the resource directory to work with"`
+
+	Rules  string `type:"existingfile" help:"the tag rules file, defaults to tag_rules.jsonc in the resource directory"`
+	DryRun bool   `help:"print the changes without saving them"`
+}
+
+// loadTagRules loads a tag rules file, or the one in the package folder if rulesPath is empty
+func loadTagRules(l log.FieldLogger, pkg *ddpackage.Package, rulesPath string) (*ddpackage.TagRules, error) {
+	if rulesPath == "" {
+		rulesPath = pkg.TagRulesPath()
+	}
+	rules, err := ddpackage.LoadTagRules(rulesPath)
+	if err != nil {
+		l.WithError(err).WithField("rulesPath", rulesPath).Error("failed to load tag rules")
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (tarc *TagsApplyRulesCmd) Run(ctx *Context) error {
+	err := ctx.LoadEditPkg(tarc.InputPath, tarc.DryRun)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+	rules, err := loadTagRules(ctx.Log, ctx.Pkg, tarc.Rules)
+	if err != nil {
+		return err
+	}
+
+	var diff *structures.TagsDiff
+	if tarc.DryRun {
+		_, diff = ctx.Pkg.PreviewTagRules(rules)
+	} else {
+		diff, err = ctx.Pkg.ApplyTagRules(rules)
+		if err != nil {
+			ctx.Log.WithError(err).Error("failed to save tags")
+			return err
+		}
+	}
+
+	if diff.Empty() {
+		fmt.Println("tags are up to date with the rules")
+		return nil
+	}
+	printTagsDiff(diff)
+	return nil
+}
+
+// printTagsDiff lists the tags and set memberships added and removed by a change to the tags
+func printTagsDiff(diff *structures.TagsDiff) {
+	if len(diff.AddedTags) > 0 {
+		fmt.Printf("New tags: %s\n\n", strings.Join(diff.AddedTags, ", "))
+	}
+	if len(diff.Tagged) > 0 {
+		fmt.Println("Tags added to resources:")
+		for _, tag := range diff.ChangedTags() {
+			resources, ok := diff.Tagged[tag]
+			if !ok {
+				continue
+			}
+			fmt.Printf("  %s:\n", tag)
+			for _, resource := range resources {
+				fmt.Printf("    + %s\n", resource)
+			}
+		}
+		fmt.Println()
+	}
+	if len(diff.SetAdded) > 0 {
+		fmt.Println("Tags added to sets:")
+		for _, set := range diff.ChangedSets() {
+			if tags, ok := diff.SetAdded[set]; ok {
+				fmt.Printf("  %s: %s\n", set, strings.Join(tags, ", "))
+			}
+		}
+		fmt.Println()
+	}
+	printTagsRemovals(diff)
+}
+
+type TagsCheckCmd struct {
+	InputPath string `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
+
+	Fix bool `help:"repair the problems found, only for resource directories"`
+}
+
+func (tcc *TagsCheckCmd) Run(ctx *Context) error {
+	err := ctx.LoadEditPkg(tcc.InputPath, !tcc.Fix)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	check := ctx.Pkg.CheckTags()
+	if check.Empty() {
+		fmt.Println("no problems found in the tags")
+		return nil
+	}
+	printTagsCheck(check)
+
+	if !tcc.Fix {
+		return fmt.Errorf("%d problems found in the tags, use --fix to repair them", check.Count())
+	}
+	diff, err := ctx.Pkg.FixTags()
+	if err != nil {
+		ctx.Log.WithError(err).Error("failed to save tags")
+		return err
+	}
+	fmt.Println("Repaired:")
+	printTagsDiff(diff)
+	if len(diff.RemovedSets) > 0 {
+		fmt.Printf("Removed sets: %s\n\n", strings.Join(diff.RemovedSets, ", "))
+	}
+	return nil
+}
+
+// printTagsCheck lists the problems found in the tags
+func printTagsCheck(check *ddpackage.TagsCheck) {
+	printResources := func(title string, resources map[string][]string) {
+		if len(resources) == 0 {
+			return
+		}
+		fmt.Println(title)
+		for _, tag := range slices.Sorted(maps.Keys(resources)) {
+			fmt.Printf("  %s:\n", tag)
+			for _, resource := range resources[tag] {
+				fmt.Printf("    %s\n", resource)
+			}
+		}
+		fmt.Println()
+	}
+	printResources("Tagged resources that are not in the pack:", check.Dangling)
+	printResources("Tagged resources that can not be tagged:", check.NotTaggable)
+	if len(check.EmptyTags) > 0 {
+		fmt.Printf("Tags without resources or a set: %s\n\n", strings.Join(check.EmptyTags, ", "))
+	}
+	if len(check.UnknownSetTags) > 0 {
+		fmt.Println("Sets with tags that do not exist:")
+		for _, set := range slices.Sorted(maps.Keys(check.UnknownSetTags)) {
+			fmt.Printf("  %s: %s\n", set, strings.Join(check.UnknownSetTags[set], ", "))
+		}
+		fmt.Println()
+	}
+	if len(check.EmptySets) > 0 {
+		fmt.Printf("Sets without tags: %s\n\n", strings.Join(check.EmptySets, ", "))
+	}
+	if len(check.Duplicates) > 0 {
+		fmt.Println("Tags that only differ by case or whitespace:")
+		for _, group := range check.Duplicates {
+			quoted := make([]string, len(group))
+			for i, tag := range group {
+				quoted[i] = fmt.Sprintf("%q", tag)
+			}
+			fmt.Printf("  %s\n", strings.Join(quoted, ", "))
+		}
+		fmt.Println()
+	}
+}
+
+type TagsStatsCmd struct {
+	InputPath string `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
+
+	ManyTags int  `help:"list resources with at least this many tags, defaults to two standard deviations above the mean"`
+	JSON     bool `help:"print the report as json"`
+}
+
+func (tsc *TagsStatsCmd) Run(ctx *Context) error {
+	err := ctx.LoadPkg(tsc.InputPath)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	stats := ctx.Pkg.TagsStats(tsc.ManyTags)
+	if tsc.JSON {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return errors.Join(err, errors.New("failed to encode the report"))
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	printTagsStats(stats)
+	return nil
+}
+
+// printTagsStats prints a tags stats report as tables
+func printTagsStats(stats *ddpackage.TagsStats) {
+	fmt.Printf("%d of %d taggable resources tagged, %.1f%% coverage, %.1f tags per tagged resource\n\n",
+		stats.Tagged, stats.Resources, stats.Coverage, stats.MeanTags)
+
+	// most used first
+	byCount := func(counts map[string]int) []string {
+		keys := slices.Collect(maps.Keys(counts))
+		slices.SortFunc(keys, func(a, b string) int {
+			return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+		})
+		return keys
+	}
+
+	if len(stats.Tags) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  TAG\tRESOURCES")
+		for _, tag := range byCount(stats.Tags) {
+			fmt.Fprintf(w, "  %s\t%d\n", tag, stats.Tags[tag])
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	if len(stats.Sets) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  SET\tTAGS")
+		for _, set := range byCount(stats.Sets) {
+			fmt.Fprintf(w, "  %s\t%d\n", set, stats.Sets[set])
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	if len(stats.Folders) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  FOLDER\tRESOURCES\tTAGGED\tCOVERAGE")
+		for _, fs := range stats.Folders {
+			fmt.Fprintf(w, "  %s\t%d\t%d\t%.1f%%\n", fs.Folder, fs.Resources, fs.Tagged, fs.Coverage)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	if len(stats.Untagged) > 0 {
+		fmt.Println("Untagged resources:")
+		for _, folder := range slices.Sorted(maps.Keys(stats.Untagged)) {
+			fmt.Printf("  %s/\n", folder)
+			for _, resource := range stats.Untagged[folder] {
+				fmt.Printf("    %s\n", path.Base(resource))
+			}
+		}
+		fmt.Println()
+	}
+	if len(stats.ManyTags) > 0 {
+		fmt.Printf("Resources with %d or more tags:\n", stats.ManyTagsThreshold)
+		for _, resource := range byCount(stats.ManyTags) {
+			fmt.Printf("  %s: %d\n", resource, stats.ManyTags[resource])
+		}
+		fmt.Println()
+	}
+}
+
+type TagsTransferCmd struct {
+	SourcePath string `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to copy tags from"`
+	TargetPath string `arg:"" type:"existingdir" help:"the resource directory to copy tags to"`
+
+	Match       []string `enum:"path,hash,image" default:"path,hash" help:"how resources are matched, tried in order: path, hash (same content), image (similar looking)"`
+	MaxDistance int      `default:"6" help:"most bits the image hashes of resources matched by image may differ by, out of 64"`
+	DryRun      bool     `help:"report the changes without saving them"`
+
+	Progress bool `default:"true" negatable:"" help:"show progressbar"`
+}
+
+func (ttc *TagsTransferCmd) Run(ctx *Context) error {
+	err := ctx.LoadEditPkg(ttc.TargetPath, ttc.DryRun)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+
+	sourcePath, err := filepath.Abs(ttc.SourcePath)
+	if err != nil {
+		return errors.Join(err, fmt.Errorf("could not get absolute path for %s", ttc.SourcePath))
+	}
+	sl := ctx.Log.WithField("sourcePath", sourcePath)
+	source, err := loadPackage(sl, sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	err = source.LoadTags()
+	if err != nil {
+		sl.WithError(err).Error("failed to load source tags")
+		return err
+	}
+
+	options := ddpackage.TagsTransferOptions{
+		MaxImageDistance: ttc.MaxDistance,
+	}
+	for _, mode := range ttc.Match {
+		options.Match = append(options.Match, ddpackage.TagsMatchMode(mode))
+	}
+
+	var tt *ddpackage.TagsTransfer
+	if ttc.Progress {
+		bar := progressbar.Default(100, "Matching Resources ...")
+		tt, err = ctx.Pkg.PreviewTagsTransferProgress(source, options, func(p float64) {
+			bar.Set(int(p * 100))
+		})
+		bar.Finish()
+	} else {
+		tt, err = ctx.Pkg.PreviewTagsTransfer(source, options)
+	}
+	if err != nil {
+		ctx.Log.WithError(err).Error("failed to match resources")
+		return err
+	}
+
+	byMode := make(map[ddpackage.TagsMatchMode]int)
+	for _, mode := range tt.MatchedBy {
+		byMode[mode] += 1
+	}
+	fmt.Printf("%d resources matched", len(tt.Matches))
+	for _, mode := range options.Match {
+		fmt.Printf(", %d by %s", byMode[mode], mode)
+	}
+	fmt.Println()
+	fmt.Println()
+
+	if len(tt.UnmatchedSource) > 0 {
+		fmt.Println("Tagged source resources without a match:")
+		for _, relPath := range tt.UnmatchedSource {
+			fmt.Printf("  %s\n", relPath)
+		}
+		fmt.Println()
+	}
+	if len(tt.UnmatchedTarget) > 0 {
+		fmt.Println("Target resources without a match:")
+		for _, relPath := range tt.UnmatchedTarget {
+			fmt.Printf("  %s\n", relPath)
+		}
+		fmt.Println()
+	}
+	if tt.Diff.Empty() {
+		fmt.Println("no tags to copy")
+		return nil
+	}
+	printTagsDiff(tt.Diff)
+	if ttc.DryRun {
+		return nil
+	}
+
+	err = ctx.Pkg.ApplyTagsTransfer(tt)
+	if err != nil {
+		ctx.Log.WithError(err).Error("failed to save tags")
+		return err
+	}
+	return nil
+}
+
+type TagsMergeCmd struct {
+	Base   string `arg:"" type:"existingfile" help:"the tags file both sides started from (git's %O)"`
+	Ours   string `arg:"" type:"existingfile" help:"our tags file, overwritten with the result unless --output is given (git's %A)"`
+	Theirs string `arg:"" type:"existingfile" help:"their tags file (git's %B)"`
+
+	Output string `short:"o" type:"path" help:"write the merged tags here instead of over <ours>"`
+}
+
+func (tmc *TagsMergeCmd) Run(ctx *Context) error {
+	var tags [3]*structures.PackageTags
+	for i, tagsPath := range []string{tmc.Base, tmc.Ours, tmc.Theirs} {
+		var err error
+		tags[i], err = ddpackage.LoadPackageTags(tagsPath)
+		if err != nil {
+			ctx.Log.WithError(err).WithField("path", tagsPath).Error("failed to load tags file")
+			return err
+		}
+	}
+
+	tm := structures.MergePackageTags(tags[0], tags[1], tags[2])
+
+	outPath := cmp.Or(tmc.Output, tmc.Ours)
+	err := ddpackage.WritePackageTags(outPath, tm.Tags)
+	if err != nil {
+		ctx.Log.WithError(err).WithField("path", outPath).Error("failed to write tags file")
+		return err
+	}
+
+	if !tm.Conflicted() {
+		return nil
+	}
+	printMergeConflicts := func(kind string, added string, conflicts []structures.TagsMergeConflict) {
+		for _, c := range conflicts {
+			deletedBy, addedBy := "theirs", "ours"
+			if c.DeletedByOurs {
+				deletedBy, addedBy = addedBy, deletedBy
+			}
+			fmt.Fprintf(
+				os.Stderr,
+				"CONFLICT: %s '%s' deleted by %s, %s added %s: %s\n",
+				kind, c.Name, deletedBy, addedBy, added, strings.Join(c.Added, ", "),
+			)
+		}
+	}
+	printMergeConflicts("tag", "resources", tm.TagConflicts)
+	printMergeConflicts("set", "tags", tm.SetConflicts)
+	return fmt.Errorf(
+		"merge conflicts: %d, the deleted tags and sets were kept with only the additions",
+		len(tm.TagConflicts)+len(tm.SetConflicts),
+	)
+}
+
+type TagsLibraryCmd struct {
+	Check TagsLibraryCheckCmd `cmd:"" help:"list tags outside of the library vocabulary and set memberships that differ from the library"`
+	Sync  TagsLibrarySyncCmd  `cmd:"" help:"rename aliased tags and update the sets of library tags to match the library"`
+	Seed  TagsLibrarySeedCmd  `cmd:"" help:"create every library set and tag in a pack, then sync it"`
+}
+
+// loadTagLibrary loads a tag library file, or the user tag library if libraryPath is empty
+func loadTagLibrary(l log.FieldLogger, libraryPath string) (*ddpackage.TagLibrary, error) {
+	if libraryPath == "" {
+		var err error
+		libraryPath, err = ddpackage.DefaultTagLibraryPath()
+		if err != nil {
+			l.WithError(err).Error("failed to find the user config directory")
+			return nil, err
+		}
+	}
+	library, err := ddpackage.LoadTagLibrary(libraryPath)
+	if err != nil {
+		l.WithError(err).WithField("libraryPath", libraryPath).Error("failed to load tag library")
+		return nil, err
+	}
+	return library, nil
+}
+
+type TagsLibraryCheckCmd struct {
+	InputPath string `arg:"" type:"path" help:"the .dungeondraft_pack file or resource directory to work with"`
+
+	Library string `type:"existingfile" help:"the tag library file, defaults to tag_library.jsonc in the dungeondraft-packager folder of the user config directory"`
+}
+
+func (tlcc *TagsLibraryCheckCmd) Run(ctx *Context) error {
+	err := ctx.LoadPkg(tlcc.InputPath)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+	library, err := loadTagLibrary(ctx.Log, tlcc.Library)
+	if err != nil {
+		return err
+	}
+
+	check := ctx.Pkg.CheckTagLibrary(library)
+	if check.Empty() {
+		fmt.Println("tags match the library")
+		return nil
+	}
+	if len(check.Unknown) > 0 {
+		fmt.Println("Tags not in the library:")
+		for _, tag := range check.Unknown {
+			fmt.Printf("  %s\n", tag)
+		}
+		fmt.Println()
+	}
+	if len(check.Aliased) > 0 {
+		fmt.Println("Tags named by an alias or another spelling:")
+		for _, tag := range slices.Sorted(maps.Keys(check.Aliased)) {
+			fmt.Printf("  %s -> %s\n", tag, check.Aliased[tag])
+		}
+		fmt.Println()
+	}
+	if len(check.MissingFromSets) > 0 {
+		fmt.Println("Tags missing from their library sets:")
+		for _, set := range slices.Sorted(maps.Keys(check.MissingFromSets)) {
+			fmt.Printf("  %s: %s\n", set, strings.Join(check.MissingFromSets[set], ", "))
+		}
+		fmt.Println()
+	}
+	if len(check.MovedFromSets) > 0 {
+		fmt.Println("Tags in sets the library has them outside of:")
+		for _, set := range slices.Sorted(maps.Keys(check.MovedFromSets)) {
+			fmt.Printf("  %s: %s\n", set, strings.Join(check.MovedFromSets[set], ", "))
+		}
+		fmt.Println()
+	}
+	return fmt.Errorf("%d differences from the library found in the tags, use tags library sync to fix them", check.Count())
+}
+
+type TagsLibrarySyncCmd struct {
+	InputPath string `arg:"" type:"path" help:"the resource directory to work with"`
+
+	Library string `type:"existingfile" help:"the tag library file, defaults to tag_library.jsonc in the dungeondraft-packager folder of the user config directory"`
+	DryRun  bool   `help:"print the changes without saving them"`
+}
+
+func (tlsc *TagsLibrarySyncCmd) Run(ctx *Context) error {
+	return syncTagLibrary(ctx, tlsc.InputPath, tlsc.Library, false, tlsc.DryRun)
+}
+
+type TagsLibrarySeedCmd struct {
+	InputPath string `arg:"" type:"path" help:"the resource directory to work with"`
+
+	Library string `type:"existingfile" help:"the tag library file, defaults to tag_library.jsonc in the dungeondraft-packager folder of the user config directory"`
+	DryRun  bool   `help:"print the changes without saving them"`
+}
+
+func (tlsc *TagsLibrarySeedCmd) Run(ctx *Context) error {
+	return syncTagLibrary(ctx, tlsc.InputPath, tlsc.Library, true, tlsc.DryRun)
+}
+
+func syncTagLibrary(ctx *Context, inputPath string, libraryPath string, seed bool, dryRun bool) error {
+	err := ctx.LoadEditPkg(inputPath, dryRun)
+	if err != nil {
+		return err
+	}
+	err = ctx.LoadTags()
+	if err != nil {
+		return err
+	}
+	library, err := loadTagLibrary(ctx.Log, libraryPath)
+	if err != nil {
+		return err
+	}
+
+	var diff *structures.TagsDiff
+	if dryRun {
+		_, diff = ctx.Pkg.PreviewTagLibrarySync(library, seed)
+	} else {
+		diff, err = ctx.Pkg.SyncTagLibrary(library, seed)
+		if err != nil {
+			ctx.Log.WithError(err).Error("failed to save tags")
+			return err
+		}
+	}
+
+	if diff.Empty() {
+		fmt.Println("tags are up to date with the library")
+		return nil
+	}
+	printTagsDiff(diff)
+	return nil
+}
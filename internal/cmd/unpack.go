@@ -2,71 +2,251 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
 
 	"github.com/schollz/progressbar/v3"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
 	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
 )
 
 type UnpackCmd struct {
-	InputPath       string `arg:"" type:"path" help:"the .dungeondraft_pack file to unpack"`
-	DestinationPath string `arg:"" type:"path" help:"the destination folder path to place the unpacked files"`
+	Paths []string `arg:"" type:"path" name:"paths" help:"the .dungeondraft_pack files, or folders containing them, to unpack followed by the destination folder path to place the unpacked files"`
 
-	Overwrite   bool `short:"O" help:"overwrite output files at destination"`
+	Overwrite   bool `short:"O" help:"overwrite output files at destination, same as --conflict=overwrite"`
 	RipTextures bool `short:"R" help:"convert .tex files in the package to normal image formats (probably never needed)" `
 	Thumbnails  bool `short:"T" help:"don't ignore resource thumbnails"`
 	Progress    bool `default:"true" negatable:"" help:"show progressbar"`
+	Jobs        int  `short:"j" default:"4" help:"number of packages to unpack at the same time"`
+	ToZip       bool `help:"write each package into a <name>.zip at the destination instead of a folder"`
+
+	Conflict string `enum:"fail,overwrite,skip,skip-if-identical,rename,backup" default:"fail" help:"what to do with files that already exist at the destination (fail,overwrite,skip,skip-if-identical,rename,backup)"`
+
+	Include    []string `short:"i" help:"only extract textures matching these glob patterns (relative to the package root)"`
+	Exclude    []string `short:"x" help:"skip textures matching these glob patterns (relative to the package root)"`
+	Tags       []string `short:"t" help:"only extract textures with at least one of these tags"`
+	Categories []string `short:"c" help:"only extract textures of these categories (caves,lights,materials,objects,paths,patterns,portals,roofs,terrain,tilesets,walls)"`
 }
 
-func (uc *UnpackCmd) Run(ctx *Context) error {
-	packFilePath, pathErr := filepath.Abs(uc.InputPath)
-	if pathErr != nil {
-		return errors.Join(pathErr, errors.New("could not get absolute path for packfile"))
+type unpackResult struct {
+	PackFile string
+	Name     string
+	OutPath  string
+	Err      error
+}
+
+// collectFiles expands folders in the input paths to the files with the extension they contain
+func collectFiles(paths []string, ext string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("could not get absolute path for %s", path))
+		}
+		if !utils.DirExists(absPath) {
+			files = append(files, absPath)
+			continue
+		}
+		entries, err := os.ReadDir(absPath)
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("could not read directory %s", absPath))
+		}
+		found := 0
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ext) {
+				files = append(files, filepath.Join(absPath, entry.Name()))
+				found += 1
+			}
+		}
+		if found == 0 {
+			log.WithField("path", absPath).Warnf("no %s files in directory", ext)
+		}
 	}
+	return files, nil
+}
 
-	packFileName := filepath.Base(packFilePath)
+func (uc *UnpackCmd) Run(ctx *Context) error {
+	if len(uc.Paths) < 2 {
+		return errors.New("expected at least one package and a destination folder")
+	}
 
-	outDirPath, pathErr := filepath.Abs(uc.DestinationPath)
+	outDirPath, pathErr := filepath.Abs(uc.Paths[len(uc.Paths)-1])
 	if pathErr != nil {
 		return errors.Join(pathErr, errors.New("could not get absolute path for dest folder"))
 	}
 
+	packFiles, err := collectFiles(uc.Paths[:len(uc.Paths)-1], ".dungeondraft_pack")
+	if err != nil {
+		return err
+	}
+	if len(packFiles) == 0 {
+		return errors.New("no packages to unpack")
+	}
+
+	conflict := ddpackage.ConflictStrategy(uc.Conflict)
+	if uc.Overwrite {
+		conflict = ddpackage.ConflictOverwrite
+	}
+
+	options := ddpackage.UnpackOptions{
+		Conflict:    conflict,
+		RipTextures: uc.RipTextures,
+		Thumbnails:  uc.Thumbnails,
+		Include:     uc.Include,
+		Exclude:     uc.Exclude,
+		Tags:        uc.Tags,
+		Categories:  uc.Categories,
+	}
+
+	if len(packFiles) == 1 {
+		result := uc.unpack(packFiles[0], outDirPath, "", options, uc.Progress)
+		return result.Err
+	}
+
+	outNames := unpackOutNames(packFiles)
+	results := make([]unpackResult, len(packFiles))
+
+	var bar *progressbar.ProgressBar
+	if uc.Progress {
+		bar = progressbar.Default(int64(len(packFiles)), "Unpacking ...")
+	}
+
+	jobs := max(uc.Jobs, 1)
+	chInput := make(chan int)
+	var wg sync.WaitGroup
+
+	for j := 0; j < jobs; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range chInput {
+				results[index] = uc.unpack(packFiles[index], outDirPath, outNames[index], options, false)
+				if bar != nil {
+					bar.Add(1)
+				}
+			}
+		}()
+	}
+
+	for index := range packFiles {
+		chInput <- index
+	}
+	close(chInput)
+	wg.Wait()
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tPACKAGE\tSTATUS\tOUTPUT")
+	for _, result := range results {
+		if result.Err != nil {
+			failed += 1
+			msg := strings.ReplaceAll(result.Err.Error(), "\n", "; ")
+			fmt.Fprintf(w, "%s\t%s\tfailed\t%s\n", filepath.Base(result.PackFile), result.Name, msg)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\tok\t%s\n", filepath.Base(result.PackFile), result.Name, result.OutPath)
+		}
+	}
+	w.Flush()
+	fmt.Printf("%d unpacked, %d failed\n", len(results)-failed, failed)
+
+	if failed > 0 {
+		return fmt.Errorf("failed to unpack %d of %d packages", failed, len(results))
+	}
+	return nil
+}
+
+// unpackOutNames names the output of each package after the package. packages that share a name
+// have their ID appended so they never extract into the same folder at the same time.
+// packages that fail to load get an empty name and report the error when unpacked
+func unpackOutNames(packFiles []string) []string {
+	names := make([]string, len(packFiles))
+	ids := make([]string, len(packFiles))
+	counts := make(map[string]int)
+	for i, packFilePath := range packFiles {
+		pkg := ddpackage.NewPackage(log.WithField("filename", filepath.Base(packFilePath)))
+		if err := pkg.LoadFromPackedPath(packFilePath, nil); err != nil {
+			continue
+		}
+		names[i], ids[i] = pkg.Name(), pkg.ID()
+		counts[names[i]] += 1
+		pkg.Close()
+	}
+
+	used := make(map[string]bool)
+	for i, name := range names {
+		if name == "" {
+			continue
+		}
+		if counts[name] > 1 {
+			name = fmt.Sprintf("%s-%s", name, ids[i])
+		}
+		// packages with the same name and ID are numbered
+		outName := name
+		for n := 2; used[outName]; n++ {
+			outName = fmt.Sprintf("%s-%d", name, n)
+		}
+		used[outName] = true
+		names[i] = outName
+	}
+	return names
+}
+
+// unpack extracts a single package to a folder, or zip, under outDirPath named outName,
+// or after the package if outName is empty
+func (uc *UnpackCmd) unpack(
+	packFilePath string,
+	outDirPath string,
+	outName string,
+	options ddpackage.UnpackOptions,
+	progress bool,
+) (result unpackResult) {
+	result.PackFile = packFilePath
+
 	l := log.WithFields(log.Fields{
-		"filename": packFileName,
+		"filename": filepath.Base(packFilePath),
 		"outPath":  outDirPath,
 	})
 
 	pkg := ddpackage.NewPackage(l)
 
-	file, fileErr := os.Open(packFilePath)
-	if fileErr != nil {
-		log.WithField("path", packFilePath).WithError(fileErr).Error("could not open file for reading.")
-		return fileErr
+	err := pkg.LoadFromPackedPath(packFilePath, nil)
+	if err != nil {
+		l.WithError(err).Error("could not load package")
+		result.Err = err
+		return
 	}
+	defer pkg.Close()
 
-	defer file.Close()
+	result.Name = pkg.Name()
+	if outName == "" {
+		outName = pkg.Name()
+	}
+	result.OutPath = filepath.Join(outDirPath, outName)
 
-	options := ddpackage.UnpackOptions{
-		Overwrite:   uc.Overwrite,
-		RipTextures: uc.RipTextures,
-		Thumbnails:  uc.Thumbnails,
+	extract := pkg.ExtractPackageProgress
+	if uc.ToZip {
+		result.OutPath += ".zip"
+		extract = pkg.ExtractPackageZipProgress
 	}
-	var err error
-	if uc.Progress {
+
+	var progressCallback func(p float64)
+	if progress {
 		total := int64(len(pkg.FileList()))
 		bar := progressbar.Default(total, "Unpacking ...")
-		err = pkg.ExtractPackageProgress(outDirPath, options, func(p float64) {
+		progressCallback = func(p float64) {
 			bar.Set(int(p * float64(total)))
-		})
-	} else {
-		err = pkg.ExtractPackage(outDirPath, options)
+		}
 	}
+	err = extract(result.OutPath, options, progressCallback)
 	if err != nil {
 		l.WithError(err).Error("failed to extract package")
-		return err
+		result.Err = err
 	}
-	return nil
+	return
 }
@@ -370,6 +370,27 @@ func (a *App) setUnpackedContent(pkg *ddpackage.Package) {
 			dlg.Show()
 		},
 	)
+	manageTagsBtn := widget.NewButton(
+		lang.X("pack.manageTagsBtn.text", "Manage Tags"),
+		func() {
+			dlg := a.createTagManageDialog()
+			dlg.Show()
+		},
+	)
+	tagStatsBtn := widget.NewButton(
+		lang.X("pack.tagStatsBtn.text", "Tag Statistics"),
+		func() {
+			dlg := a.createTagStatsDialog()
+			dlg.Show()
+		},
+	)
+	tagLibraryBtn := widget.NewButton(
+		lang.X("pack.tagLibraryBtn.text", "Tag Library"),
+		func() {
+			dlg := a.createTagLibraryDialog()
+			dlg.Show()
+		},
+	)
 
 	generateTageBtn := widget.NewButton(
 		lang.X("pack.generateTagsBtn.label", "Generate Tags"),
@@ -378,6 +399,12 @@ func (a *App) setUnpackedContent(pkg *ddpackage.Package) {
 			dlg.Show()
 		})
 
+	applyTagRulesBtn := widget.NewButton(
+		lang.X("pack.applyTagRulesBtn.label", "Apply Tag Rules"),
+		func() {
+			a.applyTagRules()
+		})
+
 	packForm := container.NewVBox(
 		layouts.NewLeftExpandHBox(
 			container.New(layout.NewFormLayout(), outLbl, outEntry),
@@ -394,7 +421,11 @@ func (a *App) setUnpackedContent(pkg *ddpackage.Package) {
 				),
 				container.NewVBox(
 					generateTageBtn,
+					applyTagRulesBtn,
 					tagSetsBtn,
+					manageTagsBtn,
+					tagStatsBtn,
+					tagLibraryBtn,
 				),
 			),
 			container.NewVBox(
@@ -461,6 +492,71 @@ func (a *App) genthumbnails() {
 	}()
 }
 
+// applyTagRules applies the tag rules file of the package after confirming the changes
+func (a *App) applyTagRules() {
+	rulesPath := a.pkg.TagRulesPath()
+	rules, err := ddpackage.LoadTagRules(rulesPath)
+	if err != nil {
+		errDlg := dialog.NewError(
+			errors.Join(err, errors.New(lang.X(
+				"tagRules.load.error.text",
+				"Error loading {{.Path}}",
+				map[string]any{
+					"Path": rulesPath,
+				},
+			))),
+			a.window,
+		)
+		errDlg.Show()
+		return
+	}
+
+	_, diff := a.pkg.PreviewTagRules(rules)
+	if diff.Empty() {
+		dialog.ShowInformation(
+			lang.X("tagRules.upToDate.title", "Tag Rules"),
+			lang.X("tagRules.upToDate.msg", "The tags are up to date with the rules."),
+			a.window,
+		)
+		return
+	}
+
+	tagged := 0
+	for _, resources := range diff.Tagged {
+		tagged += len(resources)
+	}
+	setAdded := 0
+	for _, tags := range diff.SetAdded {
+		setAdded += len(tags)
+	}
+	dialog.ShowConfirm(
+		lang.X("tagRules.confirm.title", "Apply Tag Rules"),
+		lang.X(
+			"tagRules.confirm.msg",
+			"Add {{.Tagged}} tags to resources, creating {{.NewTags}} new tags, and add {{.SetAdded}} tags to sets?",
+			map[string]any{
+				"Tagged":   tagged,
+				"NewTags":  len(diff.AddedTags),
+				"SetAdded": setAdded,
+			},
+		),
+		func(confirmed bool) {
+			if !confirmed {
+				return
+			}
+			_, err := a.pkg.ApplyTagRules(rules)
+			if err != nil {
+				errDlg := dialog.NewError(
+					errors.Join(err, errors.New(lang.X("tagRules.save.error.text", "Error saving the tags"))),
+					a.window,
+				)
+				errDlg.Show()
+			}
+		},
+		a.window,
+	)
+}
+
 func (a *App) packPackage(path string, options ddpackage.PackOptions) {
 	if path == "" {
 		dialog.ShowInformation(
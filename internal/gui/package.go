@@ -40,12 +40,22 @@ func (a *App) buildPackageTreeAndInfoPane(editable bool) fyne.CanvasObject {
 	filterErrorText := canvas.NewText(lang.X("tree.filter.error", "Bad glob syntax"), theme.Color(theme.ColorNameError))
 	filterErrorText.Hide()
 	filterEntry.Validator = func(s string) error {
-		_, err := structures.GlobToRelPathRegexp(s)
+		var err error
+		if byTag, _ := displayByTag.Get(); byTag {
+			if s != "" {
+				_, err = structures.ParseTagQuery(s)
+			}
+			filterErrorText.Text = lang.X("tree.filter.queryError", "Bad tag query")
+		} else {
+			_, err = structures.GlobToRelPathRegexp(s)
+			filterErrorText.Text = lang.X("tree.filter.error", "Bad glob syntax")
+		}
 		if err != nil {
 			filterErrorText.Show()
 		} else {
 			filterErrorText.Hide()
 		}
+		filterErrorText.Refresh()
 		return err
 	}
 
@@ -60,8 +70,9 @@ func (a *App) buildPackageTreeAndInfoPane(editable bool) fyne.CanvasObject {
 			filterEntry.SetPlaceHolder(lang.X("tree.filter.placeholder.resource", "Filter with glob (e.g. */objects/**)"))
 		} else {
 			displayByTag.Set(true)
-			filterEntry.SetPlaceHolder(lang.X("tree.filter.placeholder.tags", "Filter by tag name"))
+			filterEntry.SetPlaceHolder(lang.X("tree.filter.placeholder.query", "Filter with tag query (e.g. Furniture AND NOT set:Colors)"))
 		}
+		filterEntry.Validate()
 	})
 	displayByRadio.Required = true
 	displayByRadio.Horizontal = true
@@ -220,10 +231,13 @@ func (a *App) buildPackageTree(editable bool) (*widget.Tree, binding.String, bin
 			return filtered, nil
 		}
 		if byTag {
+			query, err := structures.ParseTagQuery(treeTagQuery(filter))
+			if err != nil {
+				return nil, err
+			}
+			log.Tracef("filtering tree list with tag query '%s'", filter)
 			return filtered.Filter(func(fi *structures.FileInfo) bool {
-				return utils.Any(a.pkg.Tags().TagsFor(fi.ResPath).Values(), func(tag string) bool {
-					return strings.Contains(strings.ToLower(tag), strings.ToLower(filter))
-				})
+				return fi.IsTaggable() && query.Match(a.pkg.Tags(), fi.CalcRelPath())
 			}), nil
 		}
 		log.Tracef("filtering tree list with '%s'", filter)
@@ -237,7 +251,7 @@ func (a *App) buildPackageTree(editable bool) (*widget.Tree, binding.String, bin
 		}
 		log.Trace("rebuilding tree")
 		if byTag {
-			nodeTree = buildTagMaps(fil, a.pkg.Tags(), filter)
+			nodeTree = buildTagMaps(fil, a.pkg.Tags(), filter != "")
 		} else {
 			nodeTree = buildInfoMaps(fil)
 		}
@@ -258,6 +272,21 @@ func (a *App) buildPackageTree(editable bool) (*widget.Tree, binding.String, bin
 	return tree, boundFilter, selected, boundByTag
 }
 
+// treeTagQuery turns a tree filter into a tag query. plain text without operators, parentheses, quotes,
+// wildcards, or prefixed terms matches tag names containing it, like the filter did before tag queries
+func treeTagQuery(filter string) string {
+	if strings.ContainsAny(filter, `()"*:`) {
+		return filter
+	}
+	for _, word := range strings.Fields(filter) {
+		switch strings.ToUpper(word) {
+		case "AND", "OR", "NOT":
+			return filter
+		}
+	}
+	return "*" + strings.TrimSpace(filter) + "*"
+}
+
 func (a *App) buildInfoPane(info *structures.FileInfo, editable bool) fyne.CanvasObject {
 	tabs := make(map[string]*container.TabItem, 3)
 	tabs["Resource"] = container.NewTabItemWithIcon(
@@ -847,7 +876,8 @@ func buildInfoMaps(fil structures.FileInfoList) map[string][]string {
 	return nodeTree
 }
 
-func buildTagMaps(fil structures.FileInfoList, pt *structures.PackageTags, filter string) map[string][]string {
+// buildTagMaps groups the taggable resources by tag, when filtered only the tags holding resources are listed
+func buildTagMaps(fil structures.FileInfoList, pt *structures.PackageTags, filtered bool) map[string][]string {
 	nodeTree := make(map[string][]string)
 	for _, fi := range fil {
 		if fi.IsTaggable() {
@@ -864,7 +894,7 @@ func buildTagMaps(fil structures.FileInfoList, pt *structures.PackageTags, filte
 	allTags := pt.AllTags()
 	slices.Sort(allTags)
 	for _, tag := range allTags {
-		if filter != "" && !strings.Contains(strings.ToLower(tag), strings.ToLower(filter)) {
+		if filtered && len(nodeTree["tag://"+tag]) == 0 {
 			continue
 		}
 		if len(nodeTree["tag://"+tag]) == 0 {
@@ -1,6 +1,7 @@
 package gui
 
 import (
+	"errors"
 	"maps"
 	"os"
 	"slices"
@@ -110,6 +111,13 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 		tagSetPrefixDelimiter = [2]string{"{", "}"}
 		stripTagSetPrefix     = true
 		stripExtraPrefix      = ""
+		fromFileNames         = false
+		fileNameStopWords     = strings.Join(ddpackage.DefaultFileNameStopWords, ", ")
+		fileNameSynonyms      = ""
+		fileNameCase          = string(ddpackage.TagCaseTitle)
+		fromColors            = false
+		colorTagSet           = ddpackage.DefaultColorTagSet
+		colorPalettePath      = ""
 	)
 	generateOptions := &ddpackage.GenerateTagsOptions{
 		BuildGlobalTagSet:      buildGlobalTagSet,
@@ -136,6 +144,15 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 
 	boundStripExtraPrefix := binding.BindString(&stripExtraPrefix)
 
+	boundFromFileNames := binding.BindBool(&fromFileNames)
+	boundFileNameStopWords := binding.BindString(&fileNameStopWords)
+	boundFileNameSynonyms := binding.BindString(&fileNameSynonyms)
+	boundFileNameCase := binding.BindString(&fileNameCase)
+
+	boundFromColors := binding.BindBool(&fromColors)
+	boundColorTagSet := binding.BindString(&colorTagSet)
+	boundColorPalettePath := binding.BindString(&colorPalettePath)
+
 	lastSplitSeperator := prefixSplitSeparator
 	lastDelimiter := [2]string{tagSetPrefixDelimiter[0], tagSetPrefixDelimiter[1]}
 
@@ -153,6 +170,12 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 			}(),
 			StripTagSetPrefix: stripTagSetPrefix,
 			StripExtraPrefix:  stripExtraPrefix,
+			FromFileNames:     fromFileNames,
+			FileNameStopWords: parseStopWords(fileNameStopWords),
+			FileNameSynonyms:  parseSynonyms(fileNameSynonyms),
+			FileNameCase:      ddpackage.TagCase(fileNameCase),
+			FromColors:        fromColors,
+			ColorTagSet:       colorTagSet,
 		}
 		generator = ddpackage.NewGenerateTags(generateOptions)
 		tagsMap = generator.TagsFromPath(strings.Join(examplePathParts, "/"))
@@ -172,6 +195,12 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 		boundPFDStop,
 		boundStripTagSetPrefix,
 		boundStripExtraPrefix,
+		boundFromFileNames,
+		boundFileNameStopWords,
+		boundFileNameSynonyms,
+		boundFileNameCase,
+		boundFromColors,
+		boundColorTagSet,
 	)
 
 	examplePathLbl := widget.NewLabel(
@@ -197,7 +226,8 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 				parts[1] = "objects"
 				changed = true
 			}
-			if parts[len(parts)-1] != "object.png" {
+			// the file name only matters when tags are made from it
+			if !fromFileNames && parts[len(parts)-1] != "object.png" {
 				parts[len(parts)-1] = "object.png"
 				changed = true
 			}
@@ -215,7 +245,7 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 				return strings.ReplaceAll(part, lastSplitSeperator, prefixSplitSeparator)
 			}))
 
-			examplePathParts = slices.Concat([]string{"textures", "objects"}, newParts, []string{"object.png"})
+			examplePathParts = slices.Concat([]string{"textures", "objects"}, newParts, examplePathParts[len(examplePathParts)-1:])
 			examplePathEntry.SetText(strings.Join(examplePathParts, string(os.PathSeparator)))
 			updateTagsMap()
 			lastSplitSeperator = prefixSplitSeparator
@@ -230,7 +260,7 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 			newParts := slices.Collect(utils.Map(slices.Values(examplePathParts[2:len(examplePathParts)-1]), func(part string) string {
 				return strings.ReplaceAll(strings.ReplaceAll(part, lastDelimiter[0], tagSetPrefixDelimiter[0]), lastDelimiter[1], tagSetPrefixDelimiter[1])
 			}))
-			examplePathParts = slices.Concat([]string{"textures", "objects"}, newParts, []string{"object.png"})
+			examplePathParts = slices.Concat([]string{"textures", "objects"}, newParts, examplePathParts[len(examplePathParts)-1:])
 			examplePathEntry.SetText(strings.Join(examplePathParts, string(os.PathSeparator)))
 			updateTagsMap()
 			lastDelimiter = [2]string{tagSetPrefixDelimiter[0], tagSetPrefixDelimiter[1]}
@@ -410,7 +440,7 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 			prefixDelim.Show()
 			prefixSplit.Hide()
 		}
-		examplePathParts = slices.Concat([]string{"textures", "objects"}, newParts, []string{"object.png"})
+		examplePathParts = slices.Concat([]string{"textures", "objects"}, newParts, examplePathParts[len(examplePathParts)-1:])
 		examplePathEntry.SetText(strings.Join(examplePathParts, string(os.PathSeparator)))
 		updateTagsMap()
 	})
@@ -446,12 +476,131 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 		stripExtraPrefixLbl, stripExtraPrefixEntry,
 	)
 
+	fromFileNamesCheck := widget.NewCheckWithData(
+		lang.X("pathGen.fromFileNamesCheck.label", "Also make tags from the words in file names"),
+		boundFromFileNames,
+	)
+
+	stopWordsEntry := widget.NewEntryWithData(boundFileNameStopWords)
+	stopWordsEntry.Validator = nil
+	stopWordsLbl := widget.NewLabel(
+		lang.X("pathGen.fileNameStopWords.label", "Words to skip (comma separated)"),
+	)
+
+	synonymsEntry := widget.NewEntryWithData(boundFileNameSynonyms)
+	synonymsEntry.MultiLine = true
+	synonymsEntry.SetMinRowsVisible(3)
+	synonymsEntry.Validator = nil
+	synonymsEntry.SetPlaceHolder(lang.X("pathGen.fileNameSynonyms.placeholder", "one word=Tag per line"))
+	synonymsLbl := widget.NewLabel(
+		lang.X("pathGen.fileNameSynonyms.label", "Synonyms"),
+	)
+
+	tagCases := []ddpackage.TagCase{ddpackage.TagCaseTitle, ddpackage.TagCaseLower, ddpackage.TagCaseUpper}
+	caseSelect := widget.NewSelect(
+		[]string{
+			lang.X("pathGen.fileNameCase.title", "Title Case"),
+			lang.X("pathGen.fileNameCase.lower", "lower case"),
+			lang.X("pathGen.fileNameCase.upper", "UPPER CASE"),
+		},
+		nil,
+	)
+	caseSelect.SetSelectedIndex(slices.Index(tagCases, ddpackage.TagCase(fileNameCase)))
+	caseSelect.OnChanged = func(string) {
+		boundFileNameCase.Set(string(tagCases[caseSelect.SelectedIndex()]))
+	}
+	caseLbl := widget.NewLabel(
+		lang.X("pathGen.fileNameCase.label", "Tag Case"),
+	)
+
+	fileNameContainer := container.New(
+		layout.NewFormLayout(),
+		stopWordsLbl, stopWordsEntry,
+		synonymsLbl, synonymsEntry,
+		caseLbl, caseSelect,
+	)
+	fileNameContainer.Hide()
+
+	bindings.Listen(boundFromFileNames, func(checked bool) {
+		fileName := "object.png"
+		if checked {
+			fileNameContainer.Show()
+			fileName = "WoodenTable_round_02.png"
+		} else {
+			fileNameContainer.Hide()
+		}
+		examplePathParts[len(examplePathParts)-1] = fileName
+		examplePathEntry.SetText(strings.Join(examplePathParts, string(os.PathSeparator)))
+		updateTagsMap()
+	})
+
+	fromColorsCheck := widget.NewCheckWithData(
+		lang.X("pathGen.fromColorsCheck.label", "Tag objects with the names of their dominant colors"),
+		boundFromColors,
+	)
+
+	colorTagSetEntry := widget.NewEntryWithData(boundColorTagSet)
+	colorTagSetEntry.Validator = nil
+	colorTagSetLbl := widget.NewLabel(
+		lang.X("pathGen.colorTagSet.label", "Color Tag Set Name"),
+	)
+
+	colorPaletteEntry := widget.NewEntryWithData(boundColorPalettePath)
+	colorPaletteEntry.Validator = nil
+	colorPaletteEntry.SetPlaceHolder(lang.X("pathGen.colorPalette.placeholder", "Built in palette"))
+	colorPaletteBrowseBtn := widget.NewButtonWithIcon(lang.X("browse", "Browse"), theme.FileIcon(), func() {
+		dlg := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+			if err == nil && uc != nil {
+				boundColorPalettePath.Set(uc.URI().Path())
+				uc.Close()
+			}
+		}, a.window)
+		dlg.Show()
+	})
+	colorPaletteLbl := widget.NewLabel(
+		lang.X("pathGen.colorPalette.label", "Color Palette File"),
+	)
+
+	colorsContainer := container.New(
+		layout.NewFormLayout(),
+		colorTagSetLbl, colorTagSetEntry,
+		colorPaletteLbl, layouts.NewLeftExpandHBox(colorPaletteEntry, colorPaletteBrowseBtn),
+	)
+	colorsContainer.Hide()
+
+	bindings.Listen(boundFromColors, func(checked bool) {
+		if checked {
+			colorsContainer.Show()
+		} else {
+			colorsContainer.Hide()
+		}
+	})
+
 	var genTagsDlg *dialog.CustomDialog
 
 	generateBtn := widget.NewButtonWithIcon(
 		lang.X("pathGen.generateBtl.label", "Generate"),
 		theme.ConfirmIcon(),
 		func() {
+			generateOptions.ColorPalette = nil
+			if fromColors && colorPalettePath != "" {
+				palette, err := ddpackage.LoadColorPalette(colorPalettePath)
+				if err != nil {
+					errDlg := dialog.NewError(
+						errors.Join(err, errors.New(lang.X(
+							"pathGen.colorPalette.error.text",
+							"Error loading {{.Path}}",
+							map[string]any{
+								"Path": colorPalettePath,
+							},
+						))),
+						a.window,
+					)
+					errDlg.Show()
+					return
+				}
+				generateOptions.ColorPalette = palette
+			}
 			log.Info("Generating tags...")
 			progressVal := binding.NewFloat()
 			progressBar := widget.NewProgressBarWithData(progressVal)
@@ -488,6 +637,10 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 		buildFromPrefixCheck,
 		prefixContainer,
 		stripExtraContainer,
+		fromFileNamesCheck,
+		fileNameContainer,
+		fromColorsCheck,
+		colorsContainer,
 		generateBtn,
 	)
 
@@ -514,3 +667,28 @@ func (a *App) createTagGenDialog() dialog.Dialog {
 
 	return genTagsDlg
 }
+
+// parseStopWords splits a comma separated list of words
+func parseStopWords(text string) []string {
+	var words []string
+	for _, word := range strings.Split(text, ",") {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// parseSynonyms parses word=Tag lines, the word is matched ignoring case
+func parseSynonyms(text string) map[string]string {
+	synonyms := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		word, tag, ok := strings.Cut(line, "=")
+		word = strings.ToLower(strings.TrimSpace(word))
+		if !ok || word == "" {
+			continue
+		}
+		synonyms[word] = strings.TrimSpace(tag)
+	}
+	return synonyms
+}
@@ -0,0 +1,192 @@
+package gui
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ryex/dungeondraft-gopackager/internal/gui/layouts"
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+)
+
+// tagLibraryReport describes the differences between the package tags and the library
+func tagLibraryReport(check *ddpackage.TagLibraryCheck) string {
+	if check.Empty() {
+		return lang.X("tagLibrary.report.match", "The tags match the library.")
+	}
+	var lines []string
+	if len(check.Unknown) > 0 {
+		lines = append(lines, lang.X("tagLibrary.report.unknown", "Tags not in the library:"))
+		for _, tag := range check.Unknown {
+			lines = append(lines, "    "+tag)
+		}
+	}
+	if len(check.Aliased) > 0 {
+		lines = append(lines, lang.X("tagLibrary.report.aliased", "Tags named by an alias or another spelling:"))
+		for _, tag := range slices.Sorted(maps.Keys(check.Aliased)) {
+			lines = append(lines, fmt.Sprintf("    %s → %s", tag, check.Aliased[tag]))
+		}
+	}
+	if len(check.MissingFromSets) > 0 {
+		lines = append(lines, lang.X("tagLibrary.report.missing", "Tags missing from their library sets:"))
+		for _, set := range slices.Sorted(maps.Keys(check.MissingFromSets)) {
+			lines = append(lines, fmt.Sprintf("    %s: %s", set, strings.Join(check.MissingFromSets[set], ", ")))
+		}
+	}
+	if len(check.MovedFromSets) > 0 {
+		lines = append(lines, lang.X("tagLibrary.report.moved", "Tags in sets the library has them outside of:"))
+		for _, set := range slices.Sorted(maps.Keys(check.MovedFromSets)) {
+			lines = append(lines, fmt.Sprintf("    %s: %s", set, strings.Join(check.MovedFromSets[set], ", ")))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (a *App) createTagLibraryDialog() dialog.Dialog {
+	defaultPath, _ := ddpackage.DefaultTagLibraryPath()
+	libraryPath := binding.BindPreferenceString("tagLibrary.path", a.app.Preferences())
+
+	loadLibrary := func() (*ddpackage.TagLibrary, bool) {
+		path, _ := libraryPath.Get()
+		path = cmp.Or(path, defaultPath)
+		library, err := ddpackage.LoadTagLibrary(path)
+		if err != nil {
+			errDlg := dialog.NewError(
+				errors.Join(err, errors.New(lang.X(
+					"tagLibrary.load.error.text",
+					"Error loading {{.Path}}",
+					map[string]any{
+						"Path": path,
+					},
+				))),
+				a.window,
+			)
+			errDlg.Show()
+			return nil, false
+		}
+		return library, true
+	}
+
+	report := widget.NewLabel(lang.X("tagLibrary.report.default", "Check the tags to compare them with the library."))
+	report.Wrapping = fyne.TextWrapWord
+	check := func() {
+		library, ok := loadLibrary()
+		if !ok {
+			return
+		}
+		report.SetText(tagLibraryReport(a.pkg.CheckTagLibrary(library)))
+	}
+
+	sync := func(seed bool) {
+		library, ok := loadLibrary()
+		if !ok {
+			return
+		}
+		_, diff := a.pkg.PreviewTagLibrarySync(library, seed)
+		if diff.Empty() {
+			dialog.ShowInformation(
+				lang.X("tagLibrary.upToDate.title", "Tag Library"),
+				lang.X("tagLibrary.upToDate.msg", "The tags are up to date with the library."),
+				a.window,
+			)
+			return
+		}
+
+		setChanges := 0
+		for _, tags := range diff.SetAdded {
+			setChanges += len(tags)
+		}
+		for _, tags := range diff.SetRemoved {
+			setChanges += len(tags)
+		}
+		dialog.ShowConfirm(
+			lang.X("tagLibrary.confirm.title", "Sync With Tag Library"),
+			lang.X(
+				"tagLibrary.confirm.msg",
+				"Create {{.NewTags}} tags, merge {{.Merged}} tags into library tags, and make {{.SetChanges}} changes to sets?",
+				map[string]any{
+					"NewTags":    len(diff.AddedTags),
+					"Merged":     len(diff.RemovedTags),
+					"SetChanges": setChanges,
+				},
+			),
+			func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				_, err := a.pkg.SyncTagLibrary(library, seed)
+				if err != nil {
+					errDlg := dialog.NewError(
+						errors.Join(err, errors.New(lang.X("tagLibrary.save.error.text", "Error saving the tags"))),
+						a.window,
+					)
+					errDlg.Show()
+					return
+				}
+				report.SetText(tagLibraryReport(a.pkg.CheckTagLibrary(library)))
+			},
+			a.window,
+		)
+	}
+
+	pathEntry := widget.NewEntryWithData(libraryPath)
+	pathEntry.Validator = nil
+	pathEntry.SetPlaceHolder(defaultPath)
+	browseBtn := widget.NewButtonWithIcon(lang.X("browse", "Browse"), theme.FileIcon(), func() {
+		dlg := dialog.NewFileOpen(func(uc fyne.URIReadCloser, err error) {
+			if err == nil && uc != nil {
+				libraryPath.Set(uc.URI().Path())
+				uc.Close()
+			}
+		}, a.window)
+		dlg.Show()
+	})
+
+	helpLbl := widget.NewLabel(lang.X(
+		"tagLibrary.help.text",
+		"Sync renames tags named by an alias and puts library tags in their library sets, seed also creates every library set and tag.",
+	))
+	helpLbl.Wrapping = fyne.TextWrapWord
+
+	checkBtn := widget.NewButton(lang.X("tagLibrary.checkBtn.text", "Check Tags"), check)
+	syncBtn := widget.NewButton(lang.X("tagLibrary.syncBtn.text", "Sync Tags"), func() { sync(false) })
+	seedBtn := widget.NewButton(lang.X("tagLibrary.seedBtn.text", "Seed Sets and Tags"), func() { sync(true) })
+
+	content := container.NewPadded(
+		layouts.NewBottomExpandVBox(
+			container.New(
+				layout.NewFormLayout(),
+				widget.NewLabel(lang.X("tagLibrary.path.label", "Tag Library File")),
+				layouts.NewLeftExpandHBox(pathEntry, browseBtn),
+			),
+			helpLbl,
+			container.NewHBox(checkBtn, syncBtn, seedBtn),
+			container.NewVScroll(report),
+		),
+	)
+
+	dlg := dialog.NewCustom(
+		lang.X("tagLibrary.dialog.title", "Tag Library"),
+		lang.X("tagLibrary.dialog.dismiss", "Close"),
+		content,
+		a.window,
+	)
+	dlg.Resize(
+		fyne.NewSize(
+			fyne.Min(a.window.Canvas().Size().Width, 740),
+			fyne.Min(a.window.Canvas().Size().Height, 580),
+		),
+	)
+	return dlg
+}
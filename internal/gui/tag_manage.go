@@ -0,0 +1,218 @@
+package gui
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ryex/dungeondraft-gopackager/internal/gui/layouts"
+)
+
+func (a *App) createTagManageDialog() dialog.Dialog {
+	var tags []string
+	boundTags := binding.BindStringList(&tags)
+
+	selectedTag := ""
+
+	tagList := widget.NewListWithData(
+		boundTags,
+		func() fyne.CanvasObject {
+			return layouts.NewLeftExpandHBox(
+				widget.NewLabel("template"),
+				widget.NewLabel("0"),
+			)
+		},
+		func(di binding.DataItem, co fyne.CanvasObject) {
+			c := co.(*fyne.Container)
+			tag, _ := di.(binding.String).Get()
+			c.Objects[0].(*widget.Label).SetText(tag)
+			// quote tags with stray whitespace so it can be seen
+			if tag != strings.TrimSpace(tag) {
+				c.Objects[0].(*widget.Label).SetText(fmt.Sprintf("%q", tag))
+			}
+			c.Objects[1].(*widget.Label).SetText(fmt.Sprint(a.pkg.Tags().Tags[tag].Size()))
+		},
+	)
+
+	renameEntry := widget.NewEntry()
+	mergeSelector := widget.NewSelectEntry(nil)
+
+	updateTags := func() {
+		tags = a.pkg.Tags().AllTags()
+		slices.Sort(tags)
+		boundTags.Reload()
+		mergeSelector.SetOptions(tags)
+		tagList.UnselectAll()
+		selectedTag = ""
+		renameEntry.SetText("")
+		mergeSelector.SetText("")
+	}
+	updateTags()
+
+	tagList.OnSelected = func(id widget.ListItemID) {
+		selectedTag = tags[id]
+		renameEntry.SetText(selectedTag)
+	}
+
+	renameBtn := widget.NewButtonWithIcon(
+		lang.X("tagManage.renameBtn.text", "Rename"),
+		theme.DocumentCreateIcon(),
+		func() {
+			to := renameEntry.Text
+			if selectedTag == "" || to == "" || to == selectedTag {
+				return
+			}
+			rename := func() {
+				a.pkg.Tags().RenameTag(selectedTag, to)
+				a.saveUnpackedTags()
+				updateTags()
+			}
+			if !a.pkg.Tags().TagExists(to) {
+				rename()
+				return
+			}
+			dialog.ShowConfirm(
+				lang.X("tagManage.renameExists.title", "Tag Exists"),
+				lang.X(
+					"tagManage.renameExists.msg",
+					"The tag {{.To}} already exists, merge {{.From}} into it?",
+					map[string]any{"From": selectedTag, "To": to},
+				),
+				func(confirmed bool) {
+					if confirmed {
+						rename()
+					}
+				},
+				a.window,
+			)
+		},
+	)
+
+	mergeBtn := widget.NewButtonWithIcon(
+		lang.X("tagManage.mergeBtn.text", "Merge"),
+		theme.ContentCopyIcon(),
+		func() {
+			into := mergeSelector.Text
+			if selectedTag == "" || into == "" || into == selectedTag {
+				return
+			}
+			a.pkg.Tags().MergeTags(into, selectedTag)
+			a.saveUnpackedTags()
+			updateTags()
+		},
+	)
+
+	singularize := false
+	singularizeCheck := widget.NewCheckWithData(
+		lang.X("tagManage.singularizeCheck.label", "Also merge plural tags into their singular"),
+		binding.BindBool(&singularize),
+	)
+
+	normalizeBtn := widget.NewButton(
+		lang.X("tagManage.normalizeBtn.text", "Normalize Tags"),
+		func() {
+			renamed := a.pkg.Tags().Clone().NormalizeTags(singularize)
+			if len(renamed) == 0 {
+				dialog.ShowInformation(
+					lang.X("tagManage.normalized.title", "Normalize Tags"),
+					lang.X("tagManage.normalized.msg", "The tags are already normalized."),
+					a.window,
+				)
+				return
+			}
+			var lines []string
+			for _, tag := range slices.Sorted(maps.Keys(renamed)) {
+				lines = append(lines, fmt.Sprintf("%q → %q", tag, renamed[tag]))
+			}
+			changes := widget.NewLabel(strings.Join(lines, "\n"))
+			scroll := container.NewVScroll(changes)
+			scroll.SetMinSize(fyne.NewSize(300, 200))
+			dialog.ShowCustomConfirm(
+				lang.X("tagManage.normalizeConfirm.title", "Normalize Tags"),
+				lang.X("tagManage.normalizeConfirm.confirm", "Merge"),
+				lang.X("tagManage.normalizeConfirm.dismiss", "Cancel"),
+				container.NewBorder(
+					widget.NewLabel(lang.X(
+						"tagManage.normalizeConfirm.msg",
+						"Merge {{.Count}} tags?",
+						map[string]any{"Count": len(renamed)},
+					)),
+					nil, nil, nil,
+					scroll,
+				),
+				func(confirmed bool) {
+					if !confirmed {
+						return
+					}
+					a.pkg.Tags().NormalizeTags(singularize)
+					a.saveUnpackedTags()
+					updateTags()
+				},
+				a.window,
+			)
+		},
+	)
+
+	tagsHeader := container.NewStack(
+		&canvas.Rectangle{
+			FillColor:    theme.Color(theme.ColorNameHeaderBackground),
+			CornerRadius: 4,
+		},
+		container.NewPadded(
+			widget.NewLabel(lang.X("tagManage.tags.label.text", "Tags")),
+		),
+	)
+
+	controls := container.NewVBox(
+		container.New(
+			layout.NewFormLayout(),
+			widget.NewLabel(lang.X("tagManage.rename.label", "Rename selected tag to")),
+			layouts.NewLeftExpandHBox(renameEntry, renameBtn),
+			widget.NewLabel(lang.X("tagManage.merge.label", "Merge selected tag into")),
+			layouts.NewLeftExpandHBox(mergeSelector, mergeBtn),
+		),
+		widget.NewSeparator(),
+		singularizeCheck,
+		normalizeBtn,
+	)
+
+	content := container.NewPadded(
+		layouts.NewTopExpandVBox(
+			layouts.NewBottomExpandVBox(
+				tagsHeader,
+				container.NewStack(
+					&canvas.Rectangle{
+						FillColor:    theme.Color(theme.ColorNameInputBackground),
+						CornerRadius: 4,
+					},
+					tagList,
+				),
+			),
+			controls,
+		),
+	)
+
+	dlg := dialog.NewCustom(
+		lang.X("tagManage.dialog.title", "Manage Tags"),
+		lang.X("tagManage.dialog.dismiss", "Close"),
+		content,
+		a.window,
+	)
+	dlg.Resize(
+		fyne.NewSize(
+			fyne.Min(a.window.Canvas().Size().Width, 740),
+			fyne.Min(a.window.Canvas().Size().Height, 580),
+		),
+	)
+	return dlg
+}
@@ -0,0 +1,151 @@
+package gui
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/lang"
+	"fyne.io/fyne/v2/widget"
+	"github.com/ryex/dungeondraft-gopackager/internal/gui/layouts"
+)
+
+// newStatsTable makes a read only table with a header row
+func newStatsTable(header []string, rows [][]string) *widget.Table {
+	table := widget.NewTableWithHeaders(
+		func() (int, int) {
+			return len(rows), len(header)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("template")
+		},
+		func(id widget.TableCellID, co fyne.CanvasObject) {
+			co.(*widget.Label).SetText(rows[id.Row][id.Col])
+		},
+	)
+	table.ShowHeaderColumn = false
+	table.UpdateHeader = func(id widget.TableCellID, co fyne.CanvasObject) {
+		if id.Col >= 0 {
+			co.(*widget.Label).SetText(header[id.Col])
+		}
+	}
+	table.SetColumnWidth(0, 300)
+	for col := 1; col < len(header); col++ {
+		table.SetColumnWidth(col, 100)
+	}
+	return table
+}
+
+// countRows makes table rows of names and counts, most used first
+func countRows(counts map[string]int) [][]string {
+	keys := slices.Collect(maps.Keys(counts))
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Or(cmp.Compare(counts[b], counts[a]), cmp.Compare(a, b))
+	})
+	rows := make([][]string, len(keys))
+	for i, key := range keys {
+		rows[i] = []string{key, fmt.Sprint(counts[key])}
+	}
+	return rows
+}
+
+func (a *App) createTagStatsDialog() dialog.Dialog {
+	stats := a.pkg.TagsStats(0)
+
+	summary := widget.NewLabel(lang.X(
+		"tagStats.summary.text",
+		"{{.Tagged}} of {{.Resources}} taggable resources tagged, {{.Coverage}}% coverage, {{.MeanTags}} tags per tagged resource",
+		map[string]any{
+			"Tagged":    stats.Tagged,
+			"Resources": stats.Resources,
+			"Coverage":  fmt.Sprintf("%.1f", stats.Coverage),
+			"MeanTags":  fmt.Sprintf("%.1f", stats.MeanTags),
+		},
+	))
+	summary.Wrapping = fyne.TextWrapWord
+
+	folderRows := make([][]string, len(stats.Folders))
+	for i, fs := range stats.Folders {
+		folderRows[i] = []string{fs.Folder, fmt.Sprint(fs.Resources), fmt.Sprint(fs.Tagged), fmt.Sprintf("%.1f%%", fs.Coverage)}
+	}
+
+	var untaggedRows [][]string
+	for _, folder := range slices.Sorted(maps.Keys(stats.Untagged)) {
+		for _, resource := range stats.Untagged[folder] {
+			untaggedRows = append(untaggedRows, []string{path.Base(resource), folder})
+		}
+	}
+
+	tabs := container.NewAppTabs(
+		container.NewTabItem(
+			lang.X("tagStats.tagsTab.title", "Tags"),
+			newStatsTable(
+				[]string{lang.X("tagStats.tag.header", "Tag"), lang.X("tagStats.resources.header", "Resources")},
+				countRows(stats.Tags),
+			),
+		),
+		container.NewTabItem(
+			lang.X("tagStats.setsTab.title", "Sets"),
+			newStatsTable(
+				[]string{lang.X("tagStats.set.header", "Set"), lang.X("tagStats.tags.header", "Tags")},
+				countRows(stats.Sets),
+			),
+		),
+		container.NewTabItem(
+			lang.X("tagStats.foldersTab.title", "Coverage"),
+			newStatsTable(
+				[]string{
+					lang.X("tagStats.folder.header", "Folder"),
+					lang.X("tagStats.resources.header", "Resources"),
+					lang.X("tagStats.tagged.header", "Tagged"),
+					lang.X("tagStats.coverage.header", "Coverage"),
+				},
+				folderRows,
+			),
+		),
+		container.NewTabItem(
+			lang.X("tagStats.untaggedTab.title", "Untagged"),
+			newStatsTable(
+				[]string{lang.X("tagStats.resource.header", "Resource"), lang.X("tagStats.folder.header", "Folder")},
+				untaggedRows,
+			),
+		),
+		container.NewTabItem(
+			lang.X(
+				"tagStats.manyTagsTab.title",
+				"{{.Count}}+ Tags",
+				map[string]any{"Count": stats.ManyTagsThreshold},
+			),
+			newStatsTable(
+				[]string{lang.X("tagStats.resource.header", "Resource"), lang.X("tagStats.tags.header", "Tags")},
+				countRows(stats.ManyTags),
+			),
+		),
+	)
+
+	content := container.NewPadded(
+		layouts.NewBottomExpandVBox(
+			summary,
+			tabs,
+		),
+	)
+
+	dlg := dialog.NewCustom(
+		lang.X("tagStats.dialog.title", "Tag Statistics"),
+		lang.X("tagStats.dialog.dismiss", "Close"),
+		content,
+		a.window,
+	)
+	dlg.Resize(
+		fyne.NewSize(
+			fyne.Min(a.window.Canvas().Size().Width, 740),
+			fyne.Min(a.window.Canvas().Size().Height, 580),
+		),
+	)
+	return dlg
+}
@@ -110,11 +110,23 @@ func (a *App) setPackContent(pkg *ddpackage.Package) {
 		dlg.Show()
 	})
 
-	overwriteOption := binding.NewBool()
+	conflictOption := binding.NewString()
+	conflictOption.Set(string(ddpackage.ConflictFail))
 	ripTexOption := binding.NewBool()
 	thumbnailsOption := binding.NewBool()
 
-	overwriteCheck := widget.NewCheckWithData(lang.X("unpack.option.overwrite.text", "Overwrite existing files"), overwriteOption)
+	conflictStrategies := make([]string, 0, len(ddpackage.ConflictStrategies))
+	for _, cs := range ddpackage.ConflictStrategies {
+		conflictStrategies = append(conflictStrategies, string(cs))
+	}
+	conflictLbl := widget.NewLabel(lang.X("unpack.option.conflict.label", "Existing files"))
+	conflictSelect := widget.NewSelect(
+		conflictStrategies,
+		func(s string) {
+			conflictOption.Set(s)
+		},
+	)
+	conflictSelect.SetSelected(string(ddpackage.ConflictFail))
 	ripTexCheck := widget.NewCheckWithData(lang.X("unpack.option.repTex.text", "Rip Textures to Png"), ripTexOption)
 	thumbnailsCheck := widget.NewCheckWithData(lang.X("unpack.option.thumbnails.text", "Extract thumbnails"), thumbnailsOption)
 
@@ -124,9 +136,9 @@ func (a *App) setPackContent(pkg *ddpackage.Package) {
 			log.WithError(err).Error("error collecting bound output path value")
 			return
 		}
-		overwrite, err := overwriteOption.Get()
+		conflict, err := conflictOption.Get()
 		if err != nil {
-			log.WithError(err).Error("error collecting bound overwrite value")
+			log.WithError(err).Error("error collecting bound conflict value")
 			return
 		}
 		ripTex, err := ripTexOption.Get()
@@ -140,7 +152,7 @@ func (a *App) setPackContent(pkg *ddpackage.Package) {
 			return
 		}
 		a.extractPackage(path, ddpackage.UnpackOptions{
-			Overwrite:   overwrite,
+			Conflict:    ddpackage.ConflictStrategy(conflict),
 			RipTextures: ripTex,
 			Thumbnails:  thumbnails,
 		})
@@ -175,7 +187,8 @@ func (a *App) setPackContent(pkg *ddpackage.Package) {
 			),
 			container.NewVBox(
 				container.NewHBox(
-					overwriteCheck,
+					conflictLbl,
+					conflictSelect,
 					ripTexCheck,
 					thumbnailsCheck,
 				),
@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddimage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -43,16 +44,30 @@ func DirExists(filename string) bool {
 	return info.IsDir()
 }
 
-// RipTexture detects and pulls image data from texture bytes
+// RipTexture pulls image data from texture bytes.
+// Godot stream textures are parsed, anything else is scanned for an embedded webp, png, or jpg
 func RipTexture(data []byte) (fileExt string, fileData []byte, err error) {
+	if ddimage.IsStreamTexture(data) {
+		var st *ddimage.StreamTexture
+		st, err = ddimage.ParseStreamTexture(data)
+		if err != nil {
+			return
+		}
+		return st.Export()
+	}
+
 	// webp
 	start := bytes.Index(data, []byte{0x52, 0x49, 0x46, 0x46})
-	if start >= 0 {
-		var size int32
-		err = binary.Read(bytes.NewBuffer(data[start+4:start+8]), binary.LittleEndian, size)
+	if start >= 0 && start+8 <= len(data) {
+		var size uint32
+		err = binary.Read(bytes.NewBuffer(data[start+4:start+8]), binary.LittleEndian, &size)
 		if err != nil {
 			return
 		}
+		if start+8+int(size) > len(data) {
+			err = errors.New("found WEBP start but the data is truncated")
+			return
+		}
 		fileExt = ".webp"
 		fileData = data[start : start+8+int(size)]
 		return
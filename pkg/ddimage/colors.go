@@ -0,0 +1,79 @@
+package ddimage
+
+import (
+	"cmp"
+	"image"
+	"image/color"
+	"math"
+	"slices"
+)
+
+// ColorShare is a colour found in an image and the share of the opaque pixels near it
+type ColorShare struct {
+	Color color.NRGBA
+	// fraction of the opaque pixels, 0 to 1
+	Share float64
+}
+
+// most pixels sampled along each side of an image by DominantColors
+const dominantColorsSamples = 128
+
+// DominantColors returns the colours of the pixels with an alpha of at least threshold, the same
+// alpha test as TranparentBounds, grouped into similar colours with the most common first.
+// large images are sampled on a grid
+func DominantColors(img image.Image, threshold uint8) []ColorShare {
+	bounds := img.Bounds()
+	step := max(1, max(bounds.Dx(), bounds.Dy())/dominantColorsSamples)
+
+	type bucket struct {
+		r, g, b int
+		count   int
+	}
+	// 3 bits per channel
+	var buckets [512]bucket
+	total := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			nrgba := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if nrgba.A < threshold {
+				continue
+			}
+			b := &buckets[int(nrgba.R>>5)<<6|int(nrgba.G>>5)<<3|int(nrgba.B>>5)]
+			b.r += int(nrgba.R)
+			b.g += int(nrgba.G)
+			b.b += int(nrgba.B)
+			b.count += 1
+			total += 1
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	var shares []ColorShare
+	for _, b := range buckets {
+		if b.count == 0 {
+			continue
+		}
+		shares = append(shares, ColorShare{
+			Color: color.NRGBA{
+				R: uint8(b.r / b.count),
+				G: uint8(b.g / b.count),
+				B: uint8(b.b / b.count),
+				A: 255,
+			},
+			Share: float64(b.count) / float64(total),
+		})
+	}
+	slices.SortStableFunc(shares, func(a, b ColorShare) int { return cmp.Compare(b.Share, a.Share) })
+	return shares
+}
+
+// ColorDistance is how different two colours look, using the "redmean" weighting of rgb distance
+func ColorDistance(a color.NRGBA, b color.NRGBA) float64 {
+	rMean := (float64(a.R) + float64(b.R)) / 2
+	dr := float64(a.R) - float64(b.R)
+	dg := float64(a.G) - float64(b.G)
+	db := float64(a.B) - float64(b.B)
+	return math.Sqrt((2+rMean/256)*dr*dr + 4*dg*dg + (2+(255-rMean)/256)*db*db)
+}
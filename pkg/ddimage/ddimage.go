@@ -88,16 +88,21 @@ func ConvertRGBAToNRGBA(c color.RGBA) color.NRGBA {
 }
 
 func OpenImage(path string) (image.Image, string, error) {
+	return OpenImageSized(path, SvgSize{})
+}
+
+// OpenImageSized opens an image, rasterizing svg files at the requested size
+func OpenImageSized(path string, svgSize SvgSize) (image.Image, string, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, "", errors.Join(err, fmt.Errorf("cannot open file: %s", path))
 	}
 	defer file.Close()
 
-	ext := filepath.Ext(path)
+	ext := strings.ToLower(filepath.Ext(path))
 	switch ext {
 	case ".svg":
-		img, err := ReadSvg(file)
+		img, err := ReadSvgSized(file, svgSize)
 		if err != nil {
 			return nil, "", err
 		}
@@ -116,26 +121,58 @@ func OpenImage(path string) (image.Image, string, error) {
 var ErrInvalidSVG = errors.New("invalid svg")
 
 func ReadSvg(r io.Reader) (image.Image, error) {
+	return ReadSvgSized(r, SvgSize{})
+}
+
+// ReadSvgSized reads and rasterizes an svg at the requested size
+func ReadSvgSized(r io.Reader, size SvgSize) (image.Image, error) {
 	icon, err := oksvg.ReadIconStream(r)
 	if err != nil {
 		return nil, errors.Join(err, ErrInvalidSVG)
 	}
-	return SvgToImage(icon)
+	return SvgToImageSized(icon, size)
 }
 
 func SvgBytesToImage(byts []byte) (image.Image, error) {
 	return ReadSvg(bytes.NewReader(byts))
 }
 
-func SvgToImage(icon *oksvg.SvgIcon) (image.Image, error) {
+// SvgSize controls the resolution an svg is rasterized at.
+// Size is the length in pixels of the longest side and takes precedence over DPI.
+// DPI scales the viewBox, which is taken to be at 96 dpi.
+// the zero value renders at the viewBox size
+type SvgSize struct {
+	Size int
+	DPI  float64
+}
+
+// Dimensions calculates the pixel size to render the icon at
+func (s SvgSize) Dimensions(icon *oksvg.SvgIcon) (int, int) {
 	w := icon.ViewBox.W
 	h := icon.ViewBox.H
-	rgba := image.NewRGBA(image.Rect(0, 0, int(w), int(h)))
+	scale := 1.0
+	if s.Size > 0 && w > 0 && h > 0 {
+		scale = float64(s.Size) / math.Max(w, h)
+	} else if s.DPI > 0 {
+		scale = s.DPI / 96
+	}
+	return int(math.Max(1.0, math.Round(w*scale))), int(math.Max(1.0, math.Round(h*scale)))
+}
+
+func SvgToImage(icon *oksvg.SvgIcon) (image.Image, error) {
+	return SvgToImageSized(icon, SvgSize{})
+}
+
+// SvgToImageSized rasterizes an svg icon scaled to the requested size
+func SvgToImageSized(icon *oksvg.SvgIcon, size SvgSize) (image.Image, error) {
+	w, h := size.Dimensions(icon)
+	icon.SetTarget(0, 0, float64(w), float64(h))
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
 	icon.Draw(
 		rasterx.NewDasher(
-			int(w), int(h),
+			w, h,
 			rasterx.NewScannerGV(
-				int(w), int(h),
+				w, h,
 				rgba, rgba.Bounds(),
 			),
 		),
@@ -311,6 +348,14 @@ func PngDecodeBytes(byts []byte) (image.Image, error) {
 }
 
 func BytesToImage(byts []byte) (image.Image, string, error) {
+	// webp is not registered with the image package, see the imports
+	if len(byts) >= 12 && string(byts[0:4]) == "RIFF" && string(byts[8:12]) == "WEBP" {
+		img, err := libwebp.Decode(bytes.NewReader(byts), webpoptions.DecodingOptions{})
+		if err != nil {
+			return nil, "", err
+		}
+		return img, "webp", nil
+	}
 	return image.Decode(bytes.NewReader(byts))
 }
 
@@ -406,6 +451,6 @@ func PathIsSupportedImage(path string) bool {
 func PathIsSupportedDDImage(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
 	return slices.Contains([]string{
-		".jpg", ".jpeg", ".png", ".webp", ".bmp", ".svg",
+		".jpg", ".jpeg", ".png", ".webp", ".bmp",
 	}, ext)
 }
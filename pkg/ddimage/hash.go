@@ -0,0 +1,50 @@
+package ddimage
+
+import (
+	"image"
+	"image/color"
+	"math/bits"
+)
+
+// DifferenceHash is a 64 bit perceptual hash of an image. transparent borders are cropped and
+// transparent pixels count as black, so the same art exported at another size or with other padding
+// gives a hash with few differing bits
+func DifferenceHash(img image.Image) uint64 {
+	bounds := TranparentBounds(img, 1)
+	if bounds.Dx() > 0 && bounds.Dy() > 0 {
+		img = Crop(img, bounds)
+	}
+	small := Resize(img, 9, 8, ResizeBilinear)
+
+	var lum [8][9]float64
+	b := small.Bounds()
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 9; x++ {
+			c := color.NRGBAModel.Convert(small.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			lum[y][x] = (0.299*float64(c.R) + 0.587*float64(c.G) + 0.114*float64(c.B)) * float64(c.A) / 255
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if lum[y][x] < lum[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// HashDistance is the number of bits that differ between two difference hashes
+func HashDistance(a uint64, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// FlatHash reports if a difference hash carries no detail, as for a solid colour or flat image.
+// such hashes are the same whatever the colour so they can not tell images apart
+func FlatHash(hash uint64) bool {
+	ones := bits.OnesCount64(hash)
+	return ones == 0 || ones == 64
+}
@@ -0,0 +1,217 @@
+package ddimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+)
+
+var (
+	ErrNotStreamTexture         = errors.New("not a godot stream texture")
+	ErrInvalidStreamTexture     = errors.New("invalid godot stream texture")
+	ErrUnsupportedTextureFormat = errors.New("unsupported godot texture format")
+)
+
+// StreamTextureFormat is the Godot 3 Image::Format of raw texture data
+type StreamTextureFormat uint32
+
+const (
+	TextureFormatL8 StreamTextureFormat = iota
+	TextureFormatLA8
+	TextureFormatR8
+	TextureFormatRG8
+	TextureFormatRGB8
+	TextureFormatRGBA8
+)
+
+// bits of the data format field of a stream texture header
+const (
+	streamFormatMaskImageFormat = (1 << 20) - 1
+	streamFormatBitLossless     = 1 << 20
+	streamFormatBitLossy        = 1 << 21
+	streamFormatBitStream       = 1 << 22
+	streamFormatBitHasMipmaps   = 1 << 23
+)
+
+// bytes per pixel of the supported raw formats
+var textureFormatPixelSize = map[StreamTextureFormat]int{
+	TextureFormatL8:    1,
+	TextureFormatLA8:   2,
+	TextureFormatRGB8:  3,
+	TextureFormatRGBA8: 4,
+}
+
+// StreamTexture is a Godot 3 StreamTexture, the GDST files Godot imports textures to
+type StreamTexture struct {
+	// size of the stored image
+	Width  int
+	Height int
+	// size override to display the texture at, 0 if not set
+	CustomWidth  int
+	CustomHeight int
+	// godot texture flags (mipmaps, repeat, filter, ...)
+	Flags uint32
+	// data format, the image format and compression bits
+	DataFormat uint32
+	// number of mipmap levels stored for lossless and lossy textures
+	Mipmaps int
+	// image data of the largest mipmap level.
+	// for lossless and lossy textures this is the embedded png or webp file,
+	// otherwise raw pixels in Format
+	Data []byte
+}
+
+// IsStreamTexture reports if data starts with the GDST magic
+func IsStreamTexture(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("GDST"))
+}
+
+// ParseStreamTexture parses the header and largest image of a Godot 3 stream texture
+func ParseStreamTexture(data []byte) (*StreamTexture, error) {
+	if !IsStreamTexture(data) {
+		return nil, ErrNotStreamTexture
+	}
+	r := bytes.NewReader(data[4:])
+
+	var header struct {
+		Width        uint16
+		CustomWidth  uint16
+		Height       uint16
+		CustomHeight uint16
+		Flags        uint32
+		DataFormat   uint32
+	}
+	err := binary.Read(r, binary.LittleEndian, &header)
+	if err != nil {
+		return nil, errors.Join(err, ErrInvalidStreamTexture, errors.New("truncated header"))
+	}
+
+	st := &StreamTexture{
+		Width:        int(header.Width),
+		Height:       int(header.Height),
+		CustomWidth:  int(header.CustomWidth),
+		CustomHeight: int(header.CustomHeight),
+		Flags:        header.Flags,
+		DataFormat:   header.DataFormat,
+	}
+
+	if st.Lossless() || st.Lossy() {
+		// mipmap count then each level as a size prefixed png or webp, largest first
+		var mipmaps, size uint32
+		err = binary.Read(r, binary.LittleEndian, &mipmaps)
+		if err == nil {
+			err = binary.Read(r, binary.LittleEndian, &size)
+		}
+		if err != nil {
+			return nil, errors.Join(err, ErrInvalidStreamTexture, errors.New("truncated mipmap header"))
+		}
+		if int64(size) > int64(r.Len()) || size < 4 {
+			return nil, errors.Join(ErrInvalidStreamTexture, fmt.Errorf("image data size %d out of range", size))
+		}
+		st.Mipmaps = int(mipmaps)
+		start := len(data) - r.Len()
+		// each image is prefixed with a 4 byte "PNG " or "WEBP" tag
+		st.Data = data[start+4 : start+int(size)]
+		return st, nil
+	}
+
+	pixelSize, ok := textureFormatPixelSize[st.Format()]
+	if !ok {
+		return nil, errors.Join(ErrUnsupportedTextureFormat, fmt.Errorf("image format %d", st.Format()))
+	}
+	size := st.Width * st.Height * pixelSize
+	if size > r.Len() {
+		return nil, errors.Join(ErrInvalidStreamTexture, fmt.Errorf("expected %d bytes of image data, have %d", size, r.Len()))
+	}
+	start := len(data) - r.Len()
+	st.Data = data[start : start+size]
+	return st, nil
+}
+
+// Format returns the image format of raw texture data
+func (st *StreamTexture) Format() StreamTextureFormat {
+	return StreamTextureFormat(st.DataFormat & streamFormatMaskImageFormat)
+}
+
+// Lossless reports if the texture holds a png or lossless webp
+func (st *StreamTexture) Lossless() bool {
+	return st.DataFormat&streamFormatBitLossless != 0
+}
+
+// Lossy reports if the texture holds a lossy webp
+func (st *StreamTexture) Lossy() bool {
+	return st.DataFormat&streamFormatBitLossy != 0
+}
+
+// Streamed reports if the texture was imported for streaming
+func (st *StreamTexture) Streamed() bool {
+	return st.DataFormat&streamFormatBitStream != 0
+}
+
+// HasMipmaps reports if raw texture data includes mipmaps
+func (st *StreamTexture) HasMipmaps() bool {
+	return st.DataFormat&streamFormatBitHasMipmaps != 0
+}
+
+// Image decodes the largest image in the texture
+func (st *StreamTexture) Image() (image.Image, error) {
+	if st.Lossless() || st.Lossy() {
+		img, _, err := BytesToImage(st.Data)
+		return img, err
+	}
+
+	rect := image.Rect(0, 0, st.Width, st.Height)
+	switch st.Format() {
+	case TextureFormatL8:
+		img := image.NewGray(rect)
+		copy(img.Pix, st.Data)
+		return img, nil
+	case TextureFormatRGBA8:
+		img := image.NewNRGBA(rect)
+		copy(img.Pix, st.Data)
+		return img, nil
+	case TextureFormatLA8, TextureFormatRGB8:
+		img := image.NewNRGBA(rect)
+		pixelSize := textureFormatPixelSize[st.Format()]
+		for i := 0; i < st.Width*st.Height; i++ {
+			px := st.Data[i*pixelSize : (i+1)*pixelSize]
+			var c color.NRGBA
+			if st.Format() == TextureFormatLA8 {
+				c = color.NRGBA{R: px[0], G: px[0], B: px[0], A: px[1]}
+			} else {
+				c = color.NRGBA{R: px[0], G: px[1], B: px[2], A: 0xff}
+			}
+			img.SetNRGBA(i%st.Width, i/st.Width, c)
+		}
+		return img, nil
+	}
+	return nil, errors.Join(ErrUnsupportedTextureFormat, fmt.Errorf("image format %d", st.Format()))
+}
+
+// Export returns the texture as a normal image file and its extension.
+// embedded png and webp files are returned as is, raw data is converted to png
+func (st *StreamTexture) Export() (string, []byte, error) {
+	if st.Lossless() || st.Lossy() {
+		if bytes.HasPrefix(st.Data, []byte{0x89, 0x50, 0x4E, 0x47}) {
+			return ".png", st.Data, nil
+		}
+		if bytes.HasPrefix(st.Data, []byte("RIFF")) {
+			return ".webp", st.Data, nil
+		}
+		return "", nil, errors.Join(ErrInvalidStreamTexture, errors.New("embedded image is neither png nor webp"))
+	}
+
+	img, err := st.Image()
+	if err != nil {
+		return "", nil, err
+	}
+	var buf bytes.Buffer
+	err = PngImageBytes(img, &buf)
+	if err != nil {
+		return "", nil, err
+	}
+	return ".png", buf.Bytes(), nil
+}
@@ -0,0 +1,266 @@
+package ddmap
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrBundle = errors.New("map bundle error")
+
+// BundleOptions describe the package folder created by Bundle
+type BundleOptions struct {
+	// folder to create the package in
+	Path    string
+	Name    string
+	Author  string
+	Version string
+	// id of the new package, a random id if empty
+	ID string
+}
+
+// BundleResult describes a package built from the assets of a map
+type BundleResult struct {
+	ID string
+	// number of textures copied into the bundle
+	Resources int
+	// references to packs that were not among the sources or resources missing from them
+	Unresolved []string
+	// maps the references in the map to their path in the bundle
+	Migration *Migration
+	// the pack.json of the bundle, which is its entry in the asset manifest of a map
+	PackJSON json.RawMessage
+}
+
+// Bundle copies the textures a map references from the source packages, along with their
+// thumbnails, metadata, and tags, into a new unpacked package folder.
+// the sources must be loaded with their tags.
+// textures from different packs with the same path get the id of their pack appended to the name
+func Bundle(l logrus.FieldLogger, m *Map, sources []*ddpackage.Package, options BundleOptions) (*BundleResult, error) {
+	if options.ID == "" {
+		options.ID = ddpackage.GenPackID()
+	}
+	allow3rdParty := true
+	err := ddpackage.SavePackageJSON(l, ddpackage.SavePackageJSONOptions{
+		Path:          options.Path,
+		Name:          options.Name,
+		ID:            options.ID,
+		Author:        options.Author,
+		Version:       options.Version,
+		Allow3rdParty: &allow3rdParty,
+	}, false)
+	if err != nil {
+		return nil, errors.Join(err, ErrBundle)
+	}
+	packJSON, err := os.ReadFile(filepath.Join(options.Path, "pack.json"))
+	if err != nil {
+		return nil, errors.Join(err, ErrBundle, ddpackage.ErrPackJSONRead)
+	}
+	var compactPackJSON bytes.Buffer
+	err = json.Compact(&compactPackJSON, packJSON)
+	if err != nil {
+		return nil, errors.Join(err, ErrBundle, ddpackage.ErrPackJSONParse)
+	}
+
+	sourcesByID := make(map[string]*ddpackage.Package)
+	for _, src := range sources {
+		sourcesByID[src.ID()] = src
+	}
+
+	result := &BundleResult{
+		ID:        options.ID,
+		Migration: NewMigration(),
+		PackJSON:  compactPackJSON.Bytes(),
+	}
+	tags := structures.NewPackageTags()
+	bundleRelPaths := structures.NewSet[string]()
+
+	refs := slices.Sorted(maps.Keys(m.References()))
+	for _, ref := range refs {
+		if _, ok := result.Migration.Paths[ref]; ok {
+			// already copied with its wall
+			continue
+		}
+		rl := l.WithField("ref", ref)
+		src, ok := sourcesByID[RefPackID(ref)]
+		if !ok {
+			rl.Warn("map references a pack that is not among the sources")
+			result.Unresolved = append(result.Unresolved, ref)
+			continue
+		}
+		fi, err := src.GetResourceInfo(ref)
+		if err != nil || !fi.IsTexture() {
+			rl.Warn("map references a texture missing from its pack")
+			result.Unresolved = append(result.Unresolved, ref)
+			continue
+		}
+
+		relPath := fi.CalcRelPath()
+		bundleRelPath := relPath
+		if bundleRelPaths.Has(bundleRelPath) {
+			ext := filepath.Ext(relPath)
+			bundleRelPath = fmt.Sprintf("%s_%s%s", strings.TrimSuffix(relPath, ext), src.ID(), ext)
+		}
+		bundleRelPaths.Add(bundleRelPath)
+		bundleRef, err := copyBundleTexture(src, fi, options, bundleRelPath)
+		if err != nil {
+			rl.WithError(err).Error("failed to copy texture")
+			return nil, err
+		}
+		result.Migration.Paths[ref] = bundleRef
+
+		if fi.IsWall() {
+			// the end cap is used with the wall without being referenced by the map
+			ext := filepath.Ext(ref)
+			endRef := WallEndRef(ref)
+			_, copied := result.Migration.Paths[endRef]
+			if endFi, err := src.GetResourceInfo(endRef); err == nil && !copied {
+				bundleEndRelPath := strings.TrimSuffix(bundleRelPath, ext) + "_end" + ext
+				bundleRelPaths.Add(bundleEndRelPath)
+				bundleEndRef, err := copyBundleTexture(src, endFi, options, bundleEndRelPath)
+				if err != nil {
+					rl.WithError(err).Error("failed to copy wall end cap")
+					return nil, err
+				}
+				result.Migration.Paths[endRef] = bundleEndRef
+			}
+		}
+
+		if kind := fi.Kind(); kind != nil && kind.Metadata != nil && fi.MetadataPath != "" &&
+			src.FileList().IndexOfRes(fi.MetadataPath) != -1 {
+			migration := &Migration{Paths: map[string]string{ref: bundleRef}}
+			err = copyBundleResource(src, fi.MetadataPath, options.Path, kind.Metadata.MetadataPath(bundleRelPath), migration)
+			if err != nil {
+				rl.WithError(err).Error("failed to copy metadata")
+				return nil, err
+			}
+		}
+
+		for tag := range src.Tags().TagsFor(relPath).Values() {
+			tags.Tag(tag, bundleRelPath)
+			for set, setTags := range src.Tags().Sets {
+				if setTags.Has(tag) {
+					tags.AddTagToSet(set, tag)
+				}
+			}
+		}
+
+		result.Resources += 1
+	}
+
+	if len(tags.Tags) > 0 {
+		tagsBytes, err := json.MarshalIndent(tags, "", "  ")
+		if err != nil {
+			return nil, errors.Join(err, ErrBundle, ddpackage.ErrTagsWrite)
+		}
+		err = writeBundleFile(options.Path, "data/default.dungeondraft_tags", tagsBytes)
+		if err != nil {
+			return nil, errors.Join(err, ErrBundle, ddpackage.ErrTagsWrite)
+		}
+	}
+
+	return result, nil
+}
+
+// UseBundle rewrites the map to use a bundle made from it by Bundle. the references are migrated to the bundle,
+// the bundle is added to the asset manifest, and packs the map no longer references are removed from it.
+// returns the number of references changed
+func (m *Map) UseBundle(result *BundleResult) (int, error) {
+	usedBefore := m.PackIDs()
+	changed, err := m.Migrate(result.Migration)
+	if err != nil {
+		return 0, err
+	}
+	usedAfter := m.PackIDs()
+
+	var ops []jsonPatchOp
+	manifest := m.manifest()
+	// remove from the end so the indexes of the earlier entries stay the same
+	for i := len(manifest) - 1; i >= 0; i-- {
+		id := manifest[i].ID
+		if slices.Contains(usedBefore, id) && !slices.Contains(usedAfter, id) {
+			ops = append(ops, jsonPatchOp{Op: "remove", Path: fmt.Sprintf("/header/asset_manifest/%d", i)})
+		}
+	}
+	listed := slices.ContainsFunc(manifest, func(mp *ManifestPack) bool {
+		return mp.ID == result.ID
+	})
+	switch {
+	case listed:
+	case m.Header == nil || m.Header.AssetManifest == nil:
+		ops = append(ops, jsonPatchOp{Op: "add", Path: "/header/asset_manifest", Value: []json.RawMessage{result.PackJSON}})
+	default:
+		ops = append(ops, jsonPatchOp{Op: "add", Path: "/header/asset_manifest/-", Value: result.PackJSON})
+	}
+
+	raw, err := patchJSON(m.raw, ops)
+	if err != nil {
+		return 0, errors.Join(err, ErrBundle, errors.New("failed to update the asset manifest"))
+	}
+	bundled, err := ParseMap(m.Path, raw)
+	if err != nil {
+		return 0, errors.Join(err, ErrBundle, errors.New("rewritten map is not valid json"))
+	}
+	*m = *bundled
+	return changed, nil
+}
+
+// copyBundleTexture copies a texture and its thumbnail into the bundle folder and returns its new resource path
+func copyBundleTexture(src *ddpackage.Package, fi *structures.FileInfo, options BundleOptions, relPath string) (string, error) {
+	bundleRef := fmt.Sprintf("res://packs/%s/%s", options.ID, relPath)
+	err := copyBundleResource(src, fi.ResPath, options.Path, relPath, nil)
+	if err != nil {
+		return "", err
+	}
+	if fi.ThumbnailResPath != "" && src.FileList().IndexOfRes(fi.ThumbnailResPath) != -1 {
+		hash := md5.Sum([]byte(bundleRef))
+		thumbnailRelPath := "thumbnails/" + hex.EncodeToString(hash[:]) + ".png"
+		err = copyBundleResource(src, fi.ThumbnailResPath, options.Path, thumbnailRelPath, nil)
+		if err != nil {
+			return "", err
+		}
+	}
+	return bundleRef, nil
+}
+
+// copyBundleResource copies a resource of a package into the bundle folder,
+// rewriting the resource references in it if a migration is given
+func copyBundleResource(src *ddpackage.Package, resPath string, bundlePath string, relPath string, migration *Migration) error {
+	data, err := src.LoadResource(resPath)
+	if err != nil {
+		return errors.Join(err, ErrBundle, fmt.Errorf("failed to read %s", resPath))
+	}
+	if migration != nil {
+		data, _ = rewriteRefs(data, migration.Rewrite)
+	}
+	return writeBundleFile(bundlePath, relPath, data)
+}
+
+func writeBundleFile(bundlePath string, relPath string, data []byte) error {
+	filePath := filepath.Join(bundlePath, filepath.FromSlash(relPath))
+	if utils.FileExists(filePath) {
+		return errors.Join(ErrBundle, fmt.Errorf("%s already exists", filePath))
+	}
+	err := os.MkdirAll(filepath.Dir(filePath), 0o777)
+	if err != nil {
+		return errors.Join(err, ErrBundle)
+	}
+	err = os.WriteFile(filePath, data, 0o644)
+	if err != nil {
+		return errors.Join(err, ErrBundle, fmt.Errorf("failed to write %s", filePath))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package ddmap
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+	"github.com/sirupsen/logrus"
+)
+
+func TestBundle(t *testing.T) {
+	src := testPackage(t, map[string]string{
+		"textures/objects/{Furniture} Chairs/Wooden Table.png": "table",
+		"textures/objects/barrel.png":                          "barrel",
+		"textures/walls/Stone Wall.png":                        "wall",
+		"textures/walls/Stone Wall_end.png":                    "wall end",
+		"data/walls/Stone Wall.dungeondraft_wall":              `{"path": "res:\/\/packs\/abcd1234\/textures\/walls\/Stone Wall.png", "color": "ff00ff00",}`,
+		"data/default.dungeondraft_tags":                       `{"tags": {"Tables": ["textures/objects/{Furniture} Chairs/Wooden Table.png"]}, "sets": {}}`,
+	})
+	m, err := ParseMap("test.dungeondraft_map", []byte(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l := logrus.New()
+	l.SetLevel(logrus.WarnLevel)
+	bundlePath := filepath.Join(t.TempDir(), "bundle")
+	result, err := Bundle(l, m, []*ddpackage.Package{src}, BundleOptions{
+		Path:    bundlePath,
+		Name:    "Bundle",
+		Author:  "me",
+		Version: "1",
+		ID:      "bundle00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Resources != 3 {
+		t.Errorf("Resources = %d, want 3", result.Resources)
+	}
+	if len(result.Unresolved) != 0 {
+		t.Errorf("Unresolved = %v, want none", result.Unresolved)
+	}
+
+	for _, relPath := range []string{
+		"textures/objects/{Furniture} Chairs/Wooden Table.png",
+		"textures/objects/barrel.png",
+		"textures/walls/Stone Wall.png",
+		"textures/walls/Stone Wall_end.png",
+	} {
+		if _, err := os.Stat(filepath.Join(bundlePath, filepath.FromSlash(relPath))); err != nil {
+			t.Errorf("%s was not copied into the bundle: %s", relPath, err)
+		}
+	}
+	wallData, err := os.ReadFile(filepath.Join(bundlePath, "data", "walls", "Stone Wall.dungeondraft_wall"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(wallData), `"res://packs/bundle00/textures/walls/Stone Wall.png"`) {
+		t.Errorf("wall metadata was not migrated to the bundle: %s", wallData)
+	}
+	tagsData, err := os.ReadFile(filepath.Join(bundlePath, "data", "default.dungeondraft_tags"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(tagsData), "{Furniture} Chairs/Wooden Table.png") {
+		t.Errorf("tags were not copied into the bundle: %s", tagsData)
+	}
+
+	_, err = m.UseBundle(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ids := m.PackIDs(); !slices.Equal(ids, []string{"bundle00"}) {
+		t.Errorf("PackIDs() after UseBundle = %v, want [bundle00]", ids)
+	}
+	if len(m.Header.AssetManifest) != 1 || m.Header.AssetManifest[0].ID != "bundle00" {
+		t.Errorf("asset manifest after UseBundle = %v, want only the bundle", m.Header.AssetManifest)
+	}
+}
@@ -0,0 +1,180 @@
+// Package ddmap reads Dungeondraft .dungeondraft_map files and the pack resources they reference
+package ddmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+)
+
+var (
+	ErrMapRead  = errors.New("map read error")
+	ErrMapParse = errors.New("map parse error")
+	ErrMapWrite = errors.New("map write error")
+)
+
+// ResourceRefRegex matches a reference to a resource inside a pack, the first group is the pack id.
+// resource paths can contain spaces so a reference runs to the end of the string it is in
+var ResourceRefRegex = regexp.MustCompile(`res://packs/([^/"]+)/[^"]*`)
+
+// jsonStringRegex matches a json string literal
+var jsonStringRegex = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// Map is a loaded .dungeondraft_map file
+type Map struct {
+	// path the map was loaded from
+	Path string `json:"-"`
+
+	Header *Header `json:"header"`
+	World  *World  `json:"world"`
+
+	structures.Extra `json:"-"`
+
+	raw []byte
+}
+
+func (m *Map) UnmarshalJSON(data []byte) error {
+	type dungeondraftMap Map
+	return structures.UnmarshalObject(data, (*dungeondraftMap)(m), &m.Extra)
+}
+
+func (m Map) MarshalJSON() ([]byte, error) {
+	type dungeondraftMap Map
+	return structures.MarshalObject(dungeondraftMap(m), &m.Extra)
+}
+
+// LoadMap reads and parses a .dungeondraft_map file
+func LoadMap(path string) (*Map, error) {
+	mapBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Join(err, ErrMapRead, fmt.Errorf("failed to read %s", path))
+	}
+	return ParseMap(path, mapBytes)
+}
+
+// ParseMap parses the json of a .dungeondraft_map file, path is only recorded
+func ParseMap(path string, mapBytes []byte) (*Map, error) {
+	m := &Map{}
+	err := json.Unmarshal(mapBytes, m)
+	if err != nil {
+		return nil, errors.Join(err, ErrMapParse, fmt.Errorf("failed to parse %s", path))
+	}
+	m.Path = path
+	m.raw = mapBytes
+	return m, nil
+}
+
+// Encode re-encodes the json of the map from its typed fields.
+// call it after editing the fields, Bytes, WriteFile, References, and Migrate work on the encoded json
+func (m *Map) Encode() error {
+	mapBytes, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return errors.Join(err, ErrMapWrite)
+	}
+	m.raw = mapBytes
+	return nil
+}
+
+// Bytes returns the json of the map
+func (m *Map) Bytes() []byte {
+	return m.raw
+}
+
+// WriteFile writes the json of the map to a file
+func (m *Map) WriteFile(path string) error {
+	err := os.WriteFile(path, m.raw, 0o644)
+	if err != nil {
+		return errors.Join(err, ErrMapWrite, fmt.Errorf("failed to write %s", path))
+	}
+	return nil
+}
+
+// References counts the pack resource references in the map by resource path
+func (m *Map) References() map[string]int {
+	refs := make(map[string]int)
+	rewriteRefs(m.raw, func(ref string) string {
+		refs[ref] += 1
+		return ref
+	})
+	return refs
+}
+
+// rewriteRefs replaces the resource references in the strings of json data with the result of rewrite.
+// the strings are decoded before matching so escaped characters in a path do not end the reference.
+// returns the rewritten data and the number of references changed
+func rewriteRefs(data []byte, rewrite func(ref string) string) ([]byte, int) {
+	changed := 0
+	data = jsonStringRegex.ReplaceAllFunc(data, func(lit []byte) []byte {
+		if !bytes.Contains(lit, []byte("packs")) {
+			return lit
+		}
+		var s string
+		if json.Unmarshal(lit, &s) != nil {
+			return lit
+		}
+		loc := ResourceRefRegex.FindStringIndex(s)
+		if loc == nil {
+			return lit
+		}
+		// the reference is the rest of the string, a decoded path can hold any character
+		ref := s[loc[0]:]
+		newRef := rewrite(ref)
+		if newRef == ref {
+			return lit
+		}
+		changed += 1
+		return slices.Concat([]byte(`"`), jsonStringContent(s[:loc[0]]+newRef), []byte(`"`))
+	})
+	return data, changed
+}
+
+// PackIDs returns the sorted ids of the packs the map references resources from
+func (m *Map) PackIDs() []string {
+	ids := structures.NewSet[string]()
+	for ref := range m.References() {
+		ids.Add(RefPackID(ref))
+	}
+	packIDs := ids.AsSlice()
+	slices.Sort(packIDs)
+	return packIDs
+}
+
+// manifest returns the entries of the asset manifest, entries that are not objects are skipped
+// but keep their index
+func (m *Map) manifest() []*ManifestPack {
+	if m.Header == nil {
+		return nil
+	}
+	manifest := make([]*ManifestPack, len(m.Header.AssetManifest))
+	for i, mp := range m.Header.AssetManifest {
+		if mp == nil {
+			mp = &ManifestPack{}
+		}
+		manifest[i] = mp
+	}
+	return manifest
+}
+
+// WallEndRef returns the reference to the end cap texture of a wall, which is used with the wall
+// without the map referencing it
+func WallEndRef(ref string) string {
+	ext := filepath.Ext(ref)
+	return strings.TrimSuffix(ref, ext) + "_end" + ext
+}
+
+// RefPackID returns the pack id of a resource reference or an empty string
+func RefPackID(ref string) string {
+	match := ResourceRefRegex.FindStringSubmatch(ref)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
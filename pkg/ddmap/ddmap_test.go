@@ -0,0 +1,70 @@
+package ddmap
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+const testMap = `{
+	"header": {
+		"creation_build": "1.1.0.3",
+		"uses_default_assets": true,
+		"asset_manifest": [
+			{"name": "Test", "id": "abcd1234", "version": "1", "author": "me"}
+		]
+	},
+	"world": {
+		"format": 2,
+		"levels": {
+			"0": {
+				"label": "Ground",
+				"objects": [
+					{"texture": "res://packs/abcd1234/textures/objects/{Furniture} Chairs/Wooden Table.png"},
+					{"texture": "res://packs/abcd1234/textures/objects/{Furniture} Chairs/Wooden Table.png"},
+					{"texture": "res://packs/abcd1234/textures/objects/barrel.png"},
+					{"texture": "res://textures/objects/default.png"}
+				],
+				"walls": [
+					{"texture": "res:\/\/packs\/abcd1234\/textures\/walls\/Stone Wall.png"}
+				]
+			}
+		}
+	}
+}`
+
+func TestReferences(t *testing.T) {
+	m, err := ParseMap("test.dungeondraft_map", []byte(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		"res://packs/abcd1234/textures/objects/{Furniture} Chairs/Wooden Table.png": 2,
+		"res://packs/abcd1234/textures/objects/barrel.png":                          1,
+		"res://packs/abcd1234/textures/walls/Stone Wall.png":                        1,
+	}
+	got := m.References()
+	if !maps.Equal(got, want) {
+		t.Errorf("References() = %v, want %v", got, want)
+	}
+	if ids := m.PackIDs(); !slices.Equal(ids, []string{"abcd1234"}) {
+		t.Errorf("PackIDs() = %v, want [abcd1234]", ids)
+	}
+}
+
+func TestRefPackID(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"res://packs/abcd1234/textures/objects/{Furniture} Chairs/Wooden Table.png", "abcd1234"},
+		{"res://packs/abcd1234/textures/walls/stone.png", "abcd1234"},
+		{"res://textures/objects/default.png", ""},
+		{"abcd1234", ""},
+	}
+	for _, tt := range tests {
+		if got := RefPackID(tt.ref); got != tt.want {
+			t.Errorf("RefPackID(%q) = %q, want %q", tt.ref, got, tt.want)
+		}
+	}
+}
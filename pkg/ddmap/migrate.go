@@ -0,0 +1,178 @@
+package ddmap
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+	"github.com/tailscale/hujson"
+)
+
+var ErrBadMigration = errors.New("bad migration mapping")
+
+// Migration maps old resource references to new ones
+type Migration struct {
+	// old resource path to new resource path
+	Paths map[string]string
+	// old pack id to new pack id, applied to references without an entry in Paths
+	PackIDs map[string]string
+}
+
+func NewMigration() *Migration {
+	return &Migration{
+		Paths:   make(map[string]string),
+		PackIDs: make(map[string]string),
+	}
+}
+
+// Empty reports if the migration changes nothing
+func (mig *Migration) Empty() bool {
+	return len(mig.Paths) == 0 && len(mig.PackIDs) == 0
+}
+
+// Rewrite returns the new reference for an old one
+func (mig *Migration) Rewrite(ref string) string {
+	if newRef, ok := mig.Paths[ref]; ok {
+		return newRef
+	}
+	packID := RefPackID(ref)
+	if newID, ok := mig.PackIDs[packID]; ok {
+		return strings.Replace(ref, "res://packs/"+packID+"/", "res://packs/"+newID+"/", 1)
+	}
+	return ref
+}
+
+// MigrationFromDiff builds a migration from the moved resources of a diff between two versions of a pack,
+// and from a change of pack id
+func MigrationFromDiff(old, new *ddpackage.Package, diff *ddpackage.PackageDiff) *Migration {
+	mig := NewMigration()
+	for _, move := range diff.Moved {
+		mig.Paths[fmt.Sprintf("res://packs/%s/%s", old.ID(), move.From)] =
+			fmt.Sprintf("res://packs/%s/%s", new.ID(), move.To)
+	}
+	if old.ID() != new.ID() {
+		mig.PackIDs[old.ID()] = new.ID()
+	}
+	return mig
+}
+
+// ReadMigrationCSV reads a migration from csv rows of old,new.
+// rows of bare pack ids map a pack id, other rows must be full res:// paths.
+// an optional first row of old,new is skipped
+func ReadMigrationCSV(r io.Reader) (*Migration, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	mig := NewMigration()
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Join(err, ErrBadMigration)
+		}
+		from, to := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if line == 1 && strings.EqualFold(from, "old") && strings.EqualFold(to, "new") {
+			continue
+		}
+		switch {
+		case RefPackID(from) != "" && RefPackID(to) != "":
+			mig.Paths[from] = to
+		case !strings.ContainsAny(from, "/:") && !strings.ContainsAny(to, "/:") && from != "" && to != "":
+			mig.PackIDs[from] = to
+		default:
+			return nil, errors.Join(ErrBadMigration, fmt.Errorf("line %d: expected two res://packs/ paths or two pack ids", line))
+		}
+	}
+	return mig, nil
+}
+
+// jsonPatchOp is an RFC 6902 json patch operation
+type jsonPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+// jsonStringContent escapes s to be placed between the quotes of a json string
+func jsonStringContent(s string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	// drop the quotes and trailing newline
+	return buf.Bytes()[1 : buf.Len()-2]
+}
+
+// patchJSON applies json patch operations to json, keeping the rest of it as written
+func patchJSON(data []byte, ops []jsonPatchOp) ([]byte, error) {
+	if len(ops) == 0 {
+		return data, nil
+	}
+	ast, err := hujson.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	err = ast.Patch(patch)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		// put elements appended to an array on a line of their own like the ones before them
+		if op.Op != "add" || !strings.HasSuffix(op.Path, "/-") {
+			continue
+		}
+		parent := ast.Find(strings.TrimSuffix(op.Path, "/-"))
+		if parent == nil {
+			continue
+		}
+		if arr, ok := parent.Value.(*hujson.Array); ok && len(arr.Elements) > 1 {
+			last := len(arr.Elements) - 1
+			arr.Elements[last].BeforeExtra = slices.Clone(arr.Elements[last-1].BeforeExtra)
+		}
+	}
+	return ast.Pack(), nil
+}
+
+// Migrate rewrites the resource references and asset manifest pack ids in the map.
+// the json is edited in place so the rest of the file is kept as written.
+// returns the number of references changed
+func (m *Map) Migrate(mig *Migration) (int, error) {
+	raw, changed := rewriteRefs(m.raw, mig.Rewrite)
+	var ops []jsonPatchOp
+	for i, mp := range m.manifest() {
+		if newID, ok := mig.PackIDs[mp.ID]; ok {
+			ops = append(ops, jsonPatchOp{
+				Op:    "replace",
+				Path:  fmt.Sprintf("/header/asset_manifest/%d/id", i),
+				Value: newID,
+			})
+		}
+	}
+	raw, err := patchJSON(raw, ops)
+	if err != nil {
+		return 0, errors.Join(err, ErrBadMigration, errors.New("failed to rewrite the asset manifest"))
+	}
+	if changed == 0 && bytes.Equal(raw, m.raw) {
+		return 0, nil
+	}
+	migrated, err := ParseMap(m.Path, raw)
+	if err != nil {
+		return 0, errors.Join(err, ErrBadMigration, errors.New("migrated map is not valid json"))
+	}
+	*m = *migrated
+	return changed, nil
+}
@@ -0,0 +1,66 @@
+package ddmap
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	m, err := ParseMap("test.dungeondraft_map", []byte(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mig := NewMigration()
+	mig.Paths["res://packs/abcd1234/textures/objects/{Furniture} Chairs/Wooden Table.png"] =
+		"res://packs/abcd1234/textures/objects/{Furniture} Tables/Wooden Table.png"
+	mig.Paths["res://packs/abcd1234/textures/walls/Stone Wall.png"] =
+		`res://packs/abcd1234/textures/walls/Stone "Wall".png`
+
+	changed, err := m.Migrate(mig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 3 {
+		t.Errorf("Migrate() changed %d references, want 3", changed)
+	}
+	want := map[string]int{
+		"res://packs/abcd1234/textures/objects/{Furniture} Tables/Wooden Table.png": 2,
+		"res://packs/abcd1234/textures/objects/barrel.png":                          1,
+		`res://packs/abcd1234/textures/walls/Stone "Wall".png`:                      1,
+	}
+	if got := m.References(); !maps.Equal(got, want) {
+		t.Errorf("References() after Migrate = %v, want %v", got, want)
+	}
+	if got := m.World.Levels["0"].Walls[0].Texture; got != `res://packs/abcd1234/textures/walls/Stone "Wall".png` {
+		t.Errorf("wall texture = %q", got)
+	}
+	if strings.Contains(string(m.Bytes()), "{Furniture} Chairs") {
+		t.Error("migrated map still references the old path")
+	}
+}
+
+func TestMigratePackID(t *testing.T) {
+	m, err := ParseMap("test.dungeondraft_map", []byte(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mig := NewMigration()
+	mig.PackIDs["abcd1234"] = "efgh5678"
+
+	changed, err := m.Migrate(mig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 4 {
+		t.Errorf("Migrate() changed %d references, want 4", changed)
+	}
+	for ref := range m.References() {
+		if RefPackID(ref) != "efgh5678" {
+			t.Errorf("reference %q was not moved to the new pack id", ref)
+		}
+	}
+	if got := m.Header.AssetManifest[0].ID; got != "efgh5678" {
+		t.Errorf("asset manifest id = %q, want efgh5678", got)
+	}
+}
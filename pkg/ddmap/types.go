@@ -0,0 +1,217 @@
+package ddmap
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+)
+
+// Header is the header of a map file
+type Header struct {
+	CreationBuild     string          `json:"creation_build"`
+	UsesDefaultAssets bool            `json:"uses_default_assets"`
+	AssetManifest     []*ManifestPack `json:"asset_manifest"`
+
+	structures.Extra `json:"-"`
+}
+
+func (h *Header) UnmarshalJSON(data []byte) error {
+	type header Header
+	return structures.UnmarshalObject(data, (*header)(h), &h.Extra)
+}
+
+func (h Header) MarshalJSON() ([]byte, error) {
+	type header Header
+	return structures.MarshalObject(header(h), &h.Extra)
+}
+
+// ManifestPack is an entry in the asset manifest of a map, a pack the map was saved with
+type ManifestPack struct {
+	Name    string `json:"name"`
+	ID      string `json:"id"`
+	Version string `json:"version"`
+	Author  string `json:"author"`
+
+	structures.Extra `json:"-"`
+}
+
+func (mp *ManifestPack) UnmarshalJSON(data []byte) error {
+	type manifestPack ManifestPack
+	return structures.UnmarshalObject(data, (*manifestPack)(mp), &mp.Extra)
+}
+
+func (mp ManifestPack) MarshalJSON() ([]byte, error) {
+	type manifestPack ManifestPack
+	return structures.MarshalObject(manifestPack(mp), &mp.Extra)
+}
+
+// World holds the levels of a map
+type World struct {
+	Format     int    `json:"format"`
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	NextNodeID string `json:"next_node_id"`
+	// levels by their index
+	Levels map[string]*Level `json:"levels"`
+
+	structures.Extra `json:"-"`
+}
+
+func (w *World) UnmarshalJSON(data []byte) error {
+	type world World
+	return structures.UnmarshalObject(data, (*world)(w), &w.Extra)
+}
+
+func (w World) MarshalJSON() ([]byte, error) {
+	type world World
+	return structures.MarshalObject(world(w), &w.Extra)
+}
+
+// LevelKeys returns the keys of the levels in index order
+func (w *World) LevelKeys() []string {
+	keys := slices.Collect(maps.Keys(w.Levels))
+	slices.SortFunc(keys, func(a, b string) int {
+		// numeric strings, shorter is smaller
+		return cmp.Or(cmp.Compare(len(a), len(b)), cmp.Compare(a, b))
+	})
+	return keys
+}
+
+// Level is a floor of a map
+type Level struct {
+	Label   string    `json:"label"`
+	Objects []*Object `json:"objects"`
+	Walls   []*Wall   `json:"walls"`
+	Paths   []*Path   `json:"paths"`
+	Lights  []*Light  `json:"lights"`
+
+	structures.Extra `json:"-"`
+}
+
+func (l *Level) UnmarshalJSON(data []byte) error {
+	type level Level
+	return structures.UnmarshalObject(data, (*level)(l), &l.Extra)
+}
+
+func (l Level) MarshalJSON() ([]byte, error) {
+	type level Level
+	return structures.MarshalObject(level(l), &l.Extra)
+}
+
+// Textures returns the texture references of the objects, walls, paths, and lights of the level
+func (l *Level) Textures() []string {
+	var textures []string
+	for _, o := range l.Objects {
+		textures = append(textures, o.Texture)
+	}
+	for _, w := range l.Walls {
+		textures = append(textures, w.Texture)
+	}
+	for _, p := range l.Paths {
+		textures = append(textures, p.Texture)
+	}
+	for _, lt := range l.Lights {
+		textures = append(textures, lt.Texture)
+	}
+	return textures
+}
+
+// Object is a placed object. positions and scales are godot Vector2 strings, e.g. "Vector2( 10, 20 )"
+type Object struct {
+	Position string  `json:"position"`
+	Rotation float64 `json:"rotation"`
+	Scale    string  `json:"scale"`
+	Mirror   bool    `json:"mirror"`
+	Texture  string  `json:"texture"`
+	Layer    int     `json:"layer"`
+	Shadow   bool    `json:"shadow"`
+	NodeID   string  `json:"node_id"`
+
+	structures.Extra `json:"-"`
+}
+
+func (o *Object) UnmarshalJSON(data []byte) error {
+	type object Object
+	return structures.UnmarshalObject(data, (*object)(o), &o.Extra)
+}
+
+func (o Object) MarshalJSON() ([]byte, error) {
+	type object Object
+	return structures.MarshalObject(object(o), &o.Extra)
+}
+
+// Wall is a wall line. points are a godot PoolVector2Array string
+type Wall struct {
+	Points      string `json:"points"`
+	Texture     string `json:"texture"`
+	Color       string `json:"color"`
+	Loop        bool   `json:"loop"`
+	Type        int    `json:"type"`
+	Joint       int    `json:"joint"`
+	NormalizeUV bool   `json:"normalize_uv"`
+	Shadow      bool   `json:"shadow"`
+	NodeID      string `json:"node_id"`
+
+	structures.Extra `json:"-"`
+}
+
+func (w *Wall) UnmarshalJSON(data []byte) error {
+	type wall Wall
+	return structures.UnmarshalObject(data, (*wall)(w), &w.Extra)
+}
+
+func (w Wall) MarshalJSON() ([]byte, error) {
+	type wall Wall
+	return structures.MarshalObject(wall(w), &w.Extra)
+}
+
+// Path is a textured path line. edit points are a godot PoolVector2Array string
+type Path struct {
+	Position   string  `json:"position"`
+	Rotation   float64 `json:"rotation"`
+	Scale      string  `json:"scale"`
+	EditPoints string  `json:"edit_points"`
+	Texture    string  `json:"texture"`
+	Width      float64 `json:"width"`
+	Layer      int     `json:"layer"`
+	Loop       bool    `json:"loop"`
+	NodeID     string  `json:"node_id"`
+
+	structures.Extra `json:"-"`
+}
+
+func (p *Path) UnmarshalJSON(data []byte) error {
+	type path Path
+	return structures.UnmarshalObject(data, (*path)(p), &p.Extra)
+}
+
+func (p Path) MarshalJSON() ([]byte, error) {
+	type path Path
+	return structures.MarshalObject(path(p), &p.Extra)
+}
+
+// Light is a placed light
+type Light struct {
+	Position  string  `json:"position"`
+	Rotation  float64 `json:"rotation"`
+	Texture   string  `json:"texture"`
+	Color     string  `json:"color"`
+	Intensity float64 `json:"intensity"`
+	Range     float64 `json:"range"`
+	Shadows   bool    `json:"shadows"`
+	NodeID    string  `json:"node_id"`
+
+	structures.Extra `json:"-"`
+}
+
+func (lt *Light) UnmarshalJSON(data []byte) error {
+	type light Light
+	return structures.UnmarshalObject(data, (*light)(lt), &lt.Extra)
+}
+
+func (lt Light) MarshalJSON() ([]byte, error) {
+	type light Light
+	return structures.MarshalObject(light(lt), &lt.Extra)
+}
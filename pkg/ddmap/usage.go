@@ -0,0 +1,106 @@
+package ddmap
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+)
+
+// AssetUsage counts the references to a pack resource across a set of maps
+type AssetUsage struct {
+	ResPath string
+	Count   int
+	// paths of the maps that reference the resource
+	Maps []string
+}
+
+// MapDependencies lists the packs a map references resources from
+type MapDependencies struct {
+	Map     string
+	PackIDs []string
+}
+
+// UsageReport describes how the textures of a pack are used by a set of maps
+type UsageReport struct {
+	PackID   string
+	PackName string
+	// referenced textures of the pack, most used first.
+	// wall end caps count as used as often as their wall
+	Used []AssetUsage
+	// resource paths of textures no map references
+	Unused []string
+	// references to resources of the pack that are not in it
+	Missing []AssetUsage
+	// packs each map depends on
+	Dependencies []MapDependencies
+}
+
+// AnalyzeUsage matches the resource references of the maps against the file list of a loaded package
+func AnalyzeUsage(pkg *ddpackage.Package, maps []*Map) *UsageReport {
+	report := &UsageReport{
+		PackID:   pkg.ID(),
+		PackName: pkg.Name(),
+	}
+
+	usage := make(map[string]*AssetUsage)
+	for _, m := range maps {
+		refs := m.References()
+		for ref, count := range refs {
+			if RefPackID(ref) != pkg.ID() {
+				continue
+			}
+			au, ok := usage[ref]
+			if !ok {
+				au = &AssetUsage{ResPath: ref}
+				usage[ref] = au
+			}
+			au.Count += count
+			au.Maps = append(au.Maps, m.Path)
+		}
+		report.Dependencies = append(report.Dependencies, MapDependencies{
+			Map:     m.Path,
+			PackIDs: m.PackIDs(),
+		})
+	}
+
+	// wall end caps are used with their wall without being referenced by the maps
+	for _, fi := range pkg.FileList() {
+		if !fi.IsWall() {
+			continue
+		}
+		wallUsage, ok := usage[fi.ResPath]
+		endRef := WallEndRef(fi.ResPath)
+		if _, referenced := usage[endRef]; !ok || referenced || pkg.FileList().IndexOfRes(endRef) == -1 {
+			continue
+		}
+		usage[endRef] = &AssetUsage{ResPath: endRef, Count: wallUsage.Count, Maps: slices.Clone(wallUsage.Maps)}
+	}
+
+	for _, fi := range pkg.FileList() {
+		if !fi.IsTexture() {
+			continue
+		}
+		au, ok := usage[fi.ResPath]
+		if !ok {
+			report.Unused = append(report.Unused, fi.ResPath)
+			continue
+		}
+		report.Used = append(report.Used, *au)
+		delete(usage, fi.ResPath)
+	}
+	for _, au := range usage {
+		if pkg.FileList().IndexOfRes(au.ResPath) == -1 {
+			report.Missing = append(report.Missing, *au)
+		}
+	}
+
+	byCount := func(a, b AssetUsage) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.ResPath, b.ResPath))
+	}
+	slices.SortFunc(report.Used, byCount)
+	slices.SortFunc(report.Missing, byCount)
+	slices.Sort(report.Unused)
+
+	return report
+}
@@ -0,0 +1,89 @@
+package ddmap
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddpackage"
+	"github.com/sirupsen/logrus"
+)
+
+// testPackage writes an unpacked package folder with the given files, by path relative to the folder,
+// and loads it with its file list and tags
+func testPackage(t *testing.T, files map[string]string) *ddpackage.Package {
+	t.Helper()
+	dir := t.TempDir()
+	files["pack.json"] = `{"name": "Test", "id": "abcd1234", "version": "1", "author": "me"}`
+	for relPath, data := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(relPath))
+		err := os.MkdirAll(filepath.Dir(filePath), 0o777)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(filePath, []byte(data), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l := logrus.New()
+	l.SetLevel(logrus.WarnLevel)
+	pkg := ddpackage.NewPackage(l)
+	err := pkg.LoadUnpackedFromFolder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := pkg.BuildFileList(); len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	err = pkg.LoadTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pkg.Close)
+	return pkg
+}
+
+func TestAnalyzeUsage(t *testing.T) {
+	pkg := testPackage(t, map[string]string{
+		"textures/objects/{Furniture} Chairs/Wooden Table.png": "table",
+		"textures/objects/barrel.png":                          "barrel",
+		"textures/objects/crate.png":                           "crate",
+		"textures/walls/Stone Wall.png":                        "wall",
+		"textures/walls/Stone Wall_end.png":                    "wall end",
+		"textures/walls/brick.png":                             "brick",
+		"textures/walls/brick_end.png":                         "brick end",
+	})
+	m, err := ParseMap("test.dungeondraft_map", []byte(testMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := AnalyzeUsage(pkg, []*Map{m})
+	var used []string
+	for _, au := range report.Used {
+		used = append(used, au.ResPath)
+	}
+	wantUsed := []string{
+		"res://packs/abcd1234/textures/objects/{Furniture} Chairs/Wooden Table.png",
+		"res://packs/abcd1234/textures/objects/barrel.png",
+		"res://packs/abcd1234/textures/walls/Stone Wall.png",
+		"res://packs/abcd1234/textures/walls/Stone Wall_end.png",
+	}
+	if !slices.Equal(used, wantUsed) {
+		t.Errorf("Used = %v, want %v", used, wantUsed)
+	}
+	wantUnused := []string{
+		"res://packs/abcd1234/textures/objects/crate.png",
+		"res://packs/abcd1234/textures/walls/brick.png",
+		"res://packs/abcd1234/textures/walls/brick_end.png",
+	}
+	if !slices.Equal(report.Unused, wantUnused) {
+		t.Errorf("Unused = %v, want %v", report.Unused, wantUnused)
+	}
+	if len(report.Missing) != 0 {
+		t.Errorf("Missing = %v, want none", report.Missing)
+	}
+}
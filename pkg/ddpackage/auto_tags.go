@@ -2,9 +2,12 @@ package ddpackage
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/ryex/dungeondraft-gopackager/internal/utils"
 	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
@@ -20,13 +23,71 @@ func (p *Package) GenerateTagsProgress(generator *GenerateTags, progressCallback
 }
 
 func (p *Package) generateTags(generator *GenerateTags, pcb func(p float64)) {
+	p.addGeneratedTags(p.Tags(), generator, pcb)
+	p.SaveUnpackedTags()
+}
+
+// PreviewGenerateTags returns the package tags with the generated tags added and the changes from the current tags,
+// the package tags are not changed. with replace the tags the generator makes are first removed from every
+// taggable resource, so they end up on exactly the resources the generator tags. other tags, like ones added
+// by hand, are kept
+func (p *Package) PreviewGenerateTags(generator *GenerateTags, replace bool) (*structures.PackageTags, *structures.TagsDiff) {
+	return p.previewGenerateTags(generator, replace, nil)
+}
+
+func (p *Package) PreviewGenerateTagsProgress(
+	generator *GenerateTags,
+	replace bool,
+	progressCallback func(p float64),
+) (*structures.PackageTags, *structures.TagsDiff) {
+	return p.previewGenerateTags(generator, replace, progressCallback)
+}
+
+func (p *Package) previewGenerateTags(
+	generator *GenerateTags,
+	replace bool,
+	pcb func(p float64),
+) (*structures.PackageTags, *structures.TagsDiff) {
+	generated := structures.NewPackageTags()
+	p.addGeneratedTags(generated, generator, pcb)
+
+	tags := p.tags.Clone()
+	if replace {
+		taggable := p.taggableRelPaths()
+		for tag := range generated.Tags {
+			tags.Untag(tag, taggable...)
+		}
+	}
+	for tag, resources := range generated.Tags {
+		tags.Tag(tag, resources.AsSlice()...)
+	}
+	for set, setTags := range generated.Sets {
+		tags.AddTagToSet(set, setTags.AsSlice()...)
+	}
+	return tags, structures.DiffPackageTags(&p.tags, tags)
+}
+
+// ApplyGeneratedTags replaces the package tags with tags from PreviewGenerateTags and saves them
+func (p *Package) ApplyGeneratedTags(tags *structures.PackageTags) error {
+	p.tags = *tags.Clone()
+	return p.SaveUnpackedTags()
+}
+
+// addGeneratedTags tags the taggable resources of the package in tags with the tags the generator makes for them
+func (p *Package) addGeneratedTags(tags *structures.PackageTags, generator *GenerateTags, pcb func(p float64)) {
 	for i, fi := range p.fileList {
 		if fi.IsTaggable() {
 			tagsMap := generator.TagsFromPath(fi.CalcRelPath())
+			if generator.options.FromColors && fi.IsTexture() {
+				err := generator.addColorTags(tagsMap, fi)
+				if err != nil {
+					p.log.WithError(err).WithField("res", fi.ResPath).Warn("failed to find the colors of a texture")
+				}
+			}
 			for tag, sets := range tagsMap {
-				p.Tags().Tag(tag, fi.RelPath)
+				tags.Tag(tag, fi.RelPath)
 				for _, set := range sets.AsSlice() {
-					p.Tags().AddTagToSet(set, tag)
+					tags.AddTagToSet(set, tag)
 				}
 			}
 		}
@@ -34,9 +95,20 @@ func (p *Package) generateTags(generator *GenerateTags, pcb func(p float64)) {
 			pcb(float64(i) / float64(len(p.fileList)))
 		}
 	}
-	p.SaveUnpackedTags()
 }
 
+// TagCase is how tags generated from file names are capitalised
+type TagCase string
+
+const (
+	TagCaseTitle TagCase = "title"
+	TagCaseLower TagCase = "lower"
+	TagCaseUpper TagCase = "upper"
+)
+
+// DefaultFileNameStopWords are file name words that make poor tags
+var DefaultFileNameStopWords = []string{"a", "an", "and", "the", "of", "with", "for", "in", "on", "to"}
+
 type GenerateTagsOptions struct {
 	BuildGlobalTagSet      bool
 	GlobalTagSet           string
@@ -45,6 +117,22 @@ type GenerateTagsOptions struct {
 	TagSetPrefrixDelimiter [2]string
 	StripTagSetPrefix      bool
 	StripExtraPrefix       string
+
+	// also make a tag of each word in the file name
+	FromFileNames bool
+	// file name words that are not made into tags, compared ignoring case
+	FileNameStopWords []string
+	// maps lower case file name words to the tag to use for them, an empty tag drops the word
+	FileNameSynonyms map[string]string
+	// capitalisation of tags from file names, title case if empty
+	FileNameCase TagCase
+
+	// also tag objects with the names of their dominant colours
+	FromColors bool
+	// the colours to name, DefaultColorPalette if empty
+	ColorPalette ColorPalette
+	// set the colour tags are added to, DefaultColorTagSet if empty
+	ColorTagSet string
 }
 
 type GenerateTags struct {
@@ -68,6 +156,12 @@ func (gt *GenerateTags) TagsFromPath(path string) (tagsMap map[string]*structure
 
 	pathParts := strings.Split(path, "/")
 
+	if gt.options.FromFileNames {
+		for _, tag := range gt.TagsFromFileName(pathParts[len(pathParts)-1]) {
+			gt.addTag(tagsMap, tag, nil)
+		}
+	}
+
 	if len(pathParts) <= 3 {
 		// no potential tags in path
 		return
@@ -106,17 +200,90 @@ func (gt *GenerateTags) TagsFromPath(path string) (tagsMap map[string]*structure
 		if tag == "" {
 			continue
 		}
-		if _, ok := tagsMap[tag]; !ok {
-			tagsMap[tag] = structures.NewSet[string]()
+		gt.addTag(tagsMap, tag, sets)
+	}
+	return
+}
+
+func (gt *GenerateTags) addTag(tagsMap map[string]*structures.Set[string], tag string, sets []string) {
+	if _, ok := tagsMap[tag]; !ok {
+		tagsMap[tag] = structures.NewSet[string]()
+	}
+	for _, set := range sets {
+		tagsMap[tag].Add(set)
+	}
+	if gt.options.BuildGlobalTagSet && gt.options.GlobalTagSet != "" {
+		tagsMap[tag].Add(gt.options.GlobalTagSet)
+	}
+}
+
+var fileNameSeparatorRegex = regexp.MustCompile(`[\s_\-.,+()\[\]{}]+`)
+
+// splitFileNameWords splits a file name, without its extension, into words on separators,
+// camelCase, and letter to digit changes
+func splitFileNameWords(name string) []string {
+	var words []string
+	for _, part := range fileNameSeparatorRegex.Split(name, -1) {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			boundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+				unicode.IsDigit(prev) != unicode.IsDigit(cur) ||
+				// the last capital of an acronym starts the next word, e.g. XMLFile
+				unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if boundary {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
 		}
-		for _, set := range sets {
-			tagsMap[tag].Add(set)
+		if start < len(runes) {
+			words = append(words, string(runes[start:]))
 		}
-		if gt.options.BuildGlobalTagSet && gt.options.GlobalTagSet != "" {
-			tagsMap[tag].Add(gt.options.GlobalTagSet)
+	}
+	return words
+}
+
+// TagsFromFileName makes tags of the words in a file name. numbers, single letters,
+// and stop words are dropped, synonyms are replaced, and the case is normalised
+func (gt *GenerateTags) TagsFromFileName(fileName string) []string {
+	name := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	seen := structures.NewSet[string]()
+	var tags []string
+	for _, word := range splitFileNameWords(name) {
+		lower := strings.ToLower(word)
+		if utf8.RuneCountInString(lower) < 2 || strings.IndexFunc(lower, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+			continue
+		}
+		if slices.ContainsFunc(gt.options.FileNameStopWords, func(stop string) bool { return strings.EqualFold(stop, lower) }) {
+			continue
+		}
+		var tag string
+		if synonym, ok := gt.options.FileNameSynonyms[lower]; ok {
+			tag = strings.TrimSpace(synonym)
+		} else {
+			tag = applyTagCase(lower, gt.options.FileNameCase)
 		}
+		if tag == "" || seen.Has(tag) {
+			continue
+		}
+		seen.Add(tag)
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+func applyTagCase(word string, tagCase TagCase) string {
+	switch tagCase {
+	case TagCaseLower:
+		return strings.ToLower(word)
+	case TagCaseUpper:
+		return strings.ToUpper(word)
+	default:
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToTitle(runes[0])
+		return string(runes)
 	}
-	return
 }
 
 func (gt *GenerateTags) setupTagSetSplitter() {
@@ -0,0 +1,151 @@
+package ddpackage
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddimage"
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+	ddcolor "github.com/ryex/dungeondraft-gopackager/pkg/structures/color"
+	"github.com/tailscale/hujson"
+)
+
+// DefaultColorTagSet is the set colour tags are added to
+const DefaultColorTagSet = "Colors"
+
+const (
+	// pixels less opaque than this are ignored when finding the colours of an object
+	colorTagAlphaThreshold = 128
+	// share of the opaque pixels a palette colour needs to be a tag
+	colorTagMinShare = 0.2
+	// most colour tags given to one object
+	colorTagMax = 2
+)
+
+// ColorPalette maps colour tag names to the colour they stand for
+type ColorPalette map[string]ddcolor.Color
+
+func mustHex(hex string) ddcolor.Color {
+	c, err := ddcolor.ParseHexColorFast(hex)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// DefaultColorPalette is used when no palette is given
+var DefaultColorPalette = ColorPalette{
+	"Red":        mustHex("#b0302a"),
+	"Orange":     mustHex("#e07828"),
+	"Yellow":     mustHex("#e6c83c"),
+	"Green":      mustHex("#4a8a3a"),
+	"Teal":       mustHex("#2e8c8a"),
+	"Blue":       mustHex("#3462b4"),
+	"Purple":     mustHex("#74449c"),
+	"Pink":       mustHex("#e08cb4"),
+	"Brown":      mustHex("#5a3a22"),
+	"Wood-Brown": mustHex("#9a6a3c"),
+	"Tan":        mustHex("#d2b48c"),
+	"White":      mustHex("#f0f0ec"),
+	"Grey":       mustHex("#808080"),
+	"Black":      mustHex("#202020"),
+}
+
+// ParseColorPalette parses a palette file, a json object of tag names to hex colours.
+// comments and trailing commas are allowed
+func ParseColorPalette(data []byte) (ColorPalette, error) {
+	data, err := hujson.Standardize(data)
+	if err != nil {
+		return nil, errors.Join(err, ErrJSONStandardize, ErrColorPaletteParse)
+	}
+	palette := make(ColorPalette)
+	err = json.Unmarshal(data, &palette)
+	if err != nil {
+		return nil, errors.Join(err, ErrColorPaletteParse)
+	}
+	if len(palette) == 0 {
+		return nil, errors.Join(ErrColorPaletteParse, errors.New("palette has no colors"))
+	}
+	return palette, nil
+}
+
+// LoadColorPalette reads and parses a palette file
+func LoadColorPalette(palettePath string) (ColorPalette, error) {
+	data, err := os.ReadFile(palettePath)
+	if err != nil {
+		return nil, errors.Join(err, ErrColorPaletteRead, fmt.Errorf("failed to read %s", palettePath))
+	}
+	return ParseColorPalette(data)
+}
+
+// Nearest returns the name of the palette colour that looks closest to c
+func (cp ColorPalette) Nearest(c ddcolor.Color) string {
+	nearest := ""
+	nearestDist := 0.0
+	// sorted so ties do not depend on map order
+	for _, name := range slices.Sorted(maps.Keys(cp)) {
+		pc := cp[name]
+		dist := ddimage.ColorDistance(c.ToColor(), pc.ToColor())
+		if nearest == "" || dist < nearestDist {
+			nearest = name
+			nearestDist = dist
+		}
+	}
+	return nearest
+}
+
+// TagsFromImage returns the names of the palette colours that cover the most of the opaque pixels of the image
+func (gt *GenerateTags) TagsFromImage(img image.Image) []string {
+	palette := gt.options.ColorPalette
+	if len(palette) == 0 {
+		palette = DefaultColorPalette
+	}
+
+	shares := make(map[string]float64)
+	for _, cs := range ddimage.DominantColors(img, colorTagAlphaThreshold) {
+		shares[palette.Nearest(ddcolor.FromColor(cs.Color))] += cs.Share
+	}
+
+	names := slices.Collect(maps.Keys(shares))
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(shares[b], shares[a]), cmp.Compare(a, b))
+	})
+	var tags []string
+	for _, name := range names {
+		if shares[name] < colorTagMinShare || len(tags) >= colorTagMax {
+			break
+		}
+		tags = append(tags, name)
+	}
+	return tags
+}
+
+// colorTagSet is the set colour tags are added to
+func (gt *GenerateTags) colorTagSet() string {
+	if gt.options.ColorTagSet != "" {
+		return gt.options.ColorTagSet
+	}
+	return DefaultColorTagSet
+}
+
+// addColorTags opens the texture of a resource and adds its colour tags to the tags map
+func (gt *GenerateTags) addColorTags(tagsMap map[string]*structures.Set[string], fi *structures.FileInfo) error {
+	img := fi.Image
+	if img == nil {
+		var err error
+		img, _, err = ddimage.OpenImage(fi.Path)
+		if err != nil {
+			return errors.Join(err, fmt.Errorf("failed to open %s as an image", fi.Path))
+		}
+	}
+	for _, tag := range gt.TagsFromImage(img) {
+		gt.addTag(tagsMap, tag, []string{gt.colorTagSet()})
+	}
+	return nil
+}
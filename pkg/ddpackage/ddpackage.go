@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 
@@ -21,12 +22,72 @@ import (
 type PackOptions struct {
 	Overwrite bool
 	ValidExts []string
+	// sizes to rasterize svg textures at, keyed by texture category (the folder under textures/).
+	// the "" key applies to any category without its own entry
+	SvgSizes map[string]ddimage.SvgSize
+}
+
+// SvgSizeFor returns the svg rasterization size for a resource path relative to the package root
+func (o *PackOptions) SvgSizeFor(relPath string) ddimage.SvgSize {
+	if o == nil || o.SvgSizes == nil {
+		return ddimage.SvgSize{}
+	}
+	category, _ := utils.SplitOne(strings.TrimPrefix(relPath, "textures/"), "/")
+	if size, ok := o.SvgSizes[category]; ok {
+		return size
+	}
+	return o.SvgSizes[""]
+}
+
+// ConflictStrategy decides what happens when an extracted file already exists at the destination
+type ConflictStrategy string
+
+const (
+	// stop the extraction with an error
+	ConflictFail ConflictStrategy = "fail"
+	// replace the existing file
+	ConflictOverwrite ConflictStrategy = "overwrite"
+	// keep the existing file
+	ConflictSkip ConflictStrategy = "skip"
+	// keep the existing file if its content is the same, otherwise replace it
+	ConflictSkipIfIdentical ConflictStrategy = "skip-if-identical"
+	// write the extracted file under a new name with a numbered suffix
+	ConflictRename ConflictStrategy = "rename"
+	// move the existing file to a numbered .bak file then write the extracted file
+	ConflictBackup ConflictStrategy = "backup"
+)
+
+// ConflictStrategies lists the valid conflict strategies
+var ConflictStrategies = []ConflictStrategy{
+	ConflictFail,
+	ConflictOverwrite,
+	ConflictSkip,
+	ConflictSkipIfIdentical,
+	ConflictRename,
+	ConflictBackup,
 }
 
 type UnpackOptions struct {
 	RipTextures bool
-	Overwrite   bool
-	Thumbnails  bool
+	// Deprecated: set Conflict to ConflictOverwrite instead, Overwrite is used when Conflict is empty
+	Overwrite  bool
+	Thumbnails bool
+	// what to do with files that already exist at the destination, empty is ConflictFail
+	Conflict ConflictStrategy
+
+	// glob patterns, relative to the package root, a texture must match one of to be extracted
+	Include []string
+	// glob patterns, relative to the package root, of textures to skip
+	Exclude []string
+	// only extract textures with at least one of these tags
+	Tags []string
+	// only extract textures of these resource kinds, see structures.ResourceKinds
+	Categories []string
+}
+
+// Selective reports if the options extract only part of a package
+func (o *UnpackOptions) Selective() bool {
+	return len(o.Include) > 0 || len(o.Exclude) > 0 || len(o.Tags) > 0 || len(o.Categories) > 0
 }
 
 type PackageMode int
@@ -56,16 +117,24 @@ mode          PackageMode
 
 	walls    map[string]structures.PackageWall
 	tilesets map[string]structures.PackageTileset
+	// metadata for resource kinds registered outside of this package
+	customMetadata map[string]any
 
 	tags structures.PackageTags
 
 	pkgFile *os.File
+	// temporary folder a zipped pack folder was extracted to
+	tempDir string
 }
 
 func (p *Package) Close() {
 	if p.pkgFile != nil {
 		p.pkgFile.Close()
 	}
+	if p.tempDir != "" {
+		os.RemoveAll(p.tempDir)
+		p.tempDir = ""
+	}
 }
 
 func (p *Package) ID() string {
@@ -119,6 +188,12 @@ func (p *Package) PackedPath() string {
 	return p.packedPath
 }
 
+// Zipped reports if the package was loaded from a zipped package folder,
+// it is extracted to a temporary folder so changes to it can not be saved
+func (p *Package) Zipped() bool {
+	return p.tempDir != ""
+}
+
 func (p *Package) FileList() structures.FileInfoList {
 	p.flLock.RLock()
 	defer p.flLock.RUnlock()
@@ -143,15 +218,31 @@ func (p *Package) Tilesets() *map[string]structures.PackageTileset {
 	return &p.tilesets
 }
 
+// CustomMetadata holds metadata for resource kinds registered with structures.RegisterResourceKind,
+// keyed by the metadata file resource path. values are the type returned by the kind's MetadataKind.New
+func (p *Package) CustomMetadata() *map[string]any {
+	return &p.customMetadata
+}
+
+// metadataResPath returns the resource path of the metadata file for a texture, or "" if its kind has no metadata
+func (p *Package) metadataResPath(fi *structures.FileInfo) string {
+	kind := fi.Kind()
+	if kind == nil || kind.Metadata == nil {
+		return ""
+	}
+	return fmt.Sprintf("res://packs/%s/%s", p.id, kind.Metadata.MetadataPath(fi.CalcRelPath()))
+}
+
 func NewPackage(log logrus.FieldLogger) *Package {
 	return &Package{
-		log:         log,
-		mode:        PackageModeUnloaded,
-		alignment:   0,
-		walls:       make(map[string]structures.PackageWall),
-		tilesets:    make(map[string]structures.PackageTileset),
-		resourceMap: make(map[string]*structures.FileInfo),
-		tags:        *structures.NewPackageTags(),
+		log:            log,
+		mode:           PackageModeUnloaded,
+		alignment:      0,
+		walls:          make(map[string]structures.PackageWall),
+		tilesets:       make(map[string]structures.PackageTileset),
+		customMetadata: make(map[string]any),
+		resourceMap:    make(map[string]*structures.FileInfo),
+		tags:           *structures.NewPackageTags(),
 	}
 }
 
@@ -165,6 +256,9 @@ func (p *Package) SetAlignment(alignment int) error {
 }
 
 func (p *Package) SetUnpackOptions(options UnpackOptions) {
+	if options.Conflict == "" && options.Overwrite {
+		options.Conflict = ConflictOverwrite
+	}
 	p.unpackOptions = &options
 }
 
@@ -176,13 +270,20 @@ func (p *Package) SetPackOptions(options PackOptions) {
 }
 
 // DefaultValidExt returns a slice of valid file extensions for inclusion in a .dungeondraft_pack
+// including the metadata extensions of registered resource kinds
 func DefaultValidExt() []string {
-	return []string{
+	exts := []string{
 		".png", ".webp", ".jpg", ".jpeg",
-		".gif", ".tif", ".tiff", ".bmp",
+		".gif", ".tif", ".tiff", ".bmp", ".svg",
 		".dungeondraft_wall", ".dungeondraft_tileset",
 		".dungeondraft_tags", ".json",
 	}
+	for _, kind := range structures.ResourceKinds() {
+		if kind.Metadata != nil && !slices.Contains(exts, kind.Metadata.Ext) {
+			exts = append(exts, kind.Metadata.Ext)
+		}
+	}
+	return exts
 }
 
 func (p *Package) LoadFromPackedPath(
@@ -221,6 +322,8 @@ func (p *Package) LoadFromPackedPath(
 	return nil
 }
 
+// LoadUnpackedFromFolder loads an unpacked package from a folder, or from a .zip containing the package folder.
+// a zip is extracted to a temporary folder that is removed by Close
 func (p *Package) LoadUnpackedFromFolder(dirPath string) error {
 	dirPath, pathErr := filepath.Abs(dirPath)
 	if pathErr != nil {
@@ -230,6 +333,21 @@ func (p *Package) LoadUnpackedFromFolder(dirPath string) error {
 		return errors.Join(pathErr, errors.New("could not get absolute path for package folder"))
 	}
 
+	if IsZipPath(dirPath) {
+		tempDir, packDir, err := unzipPackFolder(dirPath)
+		if err != nil {
+			p.log.WithError(err).
+				WithField("path", dirPath).
+				Error("could not extract zipped package folder")
+			return err
+		}
+		p.log.WithField("zipPath", dirPath).
+			WithField("path", packDir).
+			Debug("extracted zipped package folder")
+		p.tempDir = tempDir
+		dirPath = packDir
+	}
+
 	if dirExists := utils.DirExists(dirPath); !dirExists {
 		err := fmt.Errorf("path %s does not exists or is not a directory", dirPath)
 		p.log.WithError(err).
@@ -284,6 +402,7 @@ func (p *Package) resetData() {
 	p.resourceMap = make(map[string]*structures.FileInfo)
 	p.walls = make(map[string]structures.PackageWall)
 	p.tilesets = make(map[string]structures.PackageTileset)
+	p.customMetadata = make(map[string]any)
 	p.tags = *structures.NewPackageTags()
 }
 
@@ -412,7 +531,7 @@ func (p *Package) NewFileInfo(options NewFileInfoOptions) (*structures.FileInfo,
 		info.ThumbnailResPath = fmt.Sprintf("res://packs/%s/thumbnails/%s", p.id, thumbnailName)
 
 		if !ddimage.PathIsSupportedDDImage(options.Path) {
-			img, format, err := ddimage.OpenImage(options.Path)
+			img, format, err := ddimage.OpenImageSized(options.Path, p.packOptions.SvgSizeFor(info.RelPath))
 			if err != nil {
 				l.WithError(err).Error("can not open path with image extension as image")
 				err = errors.Join(err, fmt.Errorf("failed to open %s as an image", options.Path))
@@ -443,20 +562,7 @@ func (p *Package) NewFileInfo(options NewFileInfoOptions) (*structures.FileInfo,
 			}
 		}
 
-		isWall := info.IsWall()
-		isTileset := info.IsTileset()
-
-		if isWall || isTileset {
-			fName := filepath.Base(info.RelPath)
-			bName := fName[:len(fName)-len(filepath.Ext(fName))]
-
-			if isWall {
-				info.MetadataPath = fmt.Sprintf("res://packs/%s/data/walls/%s.dungeondraft_wall", p.id, bName)
-			} else {
-				info.MetadataPath = fmt.Sprintf("res://packs/%s/data/tilesets/%s.dungeondraft_tileset", p.id, bName)
-			}
-		}
-
+		info.MetadataPath = p.metadataResPath(info)
 	}
 
 	return info, nil
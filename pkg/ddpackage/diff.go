@@ -0,0 +1,125 @@
+package ddpackage
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+)
+
+// ResourceMove records a resource that kept its content but changed path
+type ResourceMove struct {
+	From string
+	To   string
+}
+
+// PackageDiff describes the differences between two versions of a package.
+// Resources are identified by their path relative to the package root
+type PackageDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+	Moved   []ResourceMove
+
+	Tags *structures.TagsDiff
+}
+
+// Empty reports if the diff contains no changes
+func (d *PackageDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 &&
+		len(d.Moved) == 0 && (d.Tags == nil || d.Tags.Empty())
+}
+
+// returns true for resources that should not be compared as assets
+func (p *Package) diffIgnored(fi *structures.FileInfo) bool {
+	return fi.IsThumbnail() ||
+		fi.ResPath == fmt.Sprintf("res://packs/%s.json", p.id) ||
+		fi.CalcRelPath() == "data/default.dungeondraft_tags"
+}
+
+// ResourceHash returns the hex md5 of the data that is (or would be) stored in the package for a resource
+func (p *Package) ResourceHash(info *structures.FileInfo) (string, error) {
+	data, err := p.LoadResource(info.ResPath)
+	if err != nil {
+		return "", err
+	}
+	hash := md5.Sum(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func (p *Package) resourceHashes() (map[string]string, error) {
+	hashes := make(map[string]string)
+	var errs []error
+	for _, fi := range p.FileList() {
+		if p.diffIgnored(fi) {
+			continue
+		}
+		hash, err := p.ResourceHash(fi)
+		if err != nil {
+			errs = append(errs, errors.Join(err, fmt.Errorf("failed to hash %s", fi.ResPath)))
+			continue
+		}
+		hashes[fi.CalcRelPath()] = hash
+	}
+	return hashes, errors.Join(errs...)
+}
+
+// DiffPackages compares the resources and tags of two loaded packages.
+// the packages may be packed or unpacked, their file lists and tags must already be loaded.
+// resources are compared by content so a rebuilt pack with identical assets produces an empty diff
+func DiffPackages(old, new *Package) (*PackageDiff, error) {
+	oldHashes, err := old.resourceHashes()
+	if err != nil {
+		return nil, err
+	}
+	newHashes, err := new.resourceHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &PackageDiff{}
+	for path, hash := range newHashes {
+		oldHash, ok := oldHashes[path]
+		if !ok {
+			diff.Added = append(diff.Added, path)
+		} else if oldHash != hash {
+			diff.Changed = append(diff.Changed, path)
+		}
+	}
+	for path := range oldHashes {
+		if _, ok := newHashes[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+	slices.Sort(diff.Added)
+	slices.Sort(diff.Removed)
+	slices.Sort(diff.Changed)
+
+	// pair up removed and added resources with identical content as moves
+	removedByHash := make(map[string][]string)
+	for _, path := range diff.Removed {
+		removedByHash[oldHashes[path]] = append(removedByHash[oldHashes[path]], path)
+	}
+	movedFrom := structures.NewSet[string]()
+	movedTo := structures.NewSet[string]()
+	for _, path := range diff.Added {
+		candidates := removedByHash[newHashes[path]]
+		if len(candidates) == 0 {
+			continue
+		}
+		from := candidates[0]
+		removedByHash[newHashes[path]] = candidates[1:]
+		diff.Moved = append(diff.Moved, ResourceMove{From: from, To: path})
+		movedFrom.Add(from)
+		movedTo.Add(path)
+	}
+	diff.Added = slices.DeleteFunc(diff.Added, movedTo.Has)
+	diff.Removed = slices.DeleteFunc(diff.Removed, movedFrom.Has)
+
+	diff.Tags = structures.DiffPackageTags(old.Tags(), new.Tags())
+
+	return diff, nil
+}
@@ -15,7 +15,15 @@ var (
 	ErrTagsRead           = errors.New("tags read error")
 	ErrTagsWrite          = errors.New("tags write error")
 	ErrTagsParse          = errors.New("tag file parse error")
+	ErrTagRulesRead       = errors.New("tag rules read error")
+	ErrTagRulesParse      = errors.New("tag rules parse error")
+	ErrColorPaletteRead   = errors.New("color palette read error")
+	ErrColorPaletteParse  = errors.New("color palette parse error")
+	ErrTagLibraryRead     = errors.New("tag library read error")
+	ErrTagLibraryParse    = errors.New("tag library parse error")
 	ErrMetadataRead       = errors.New("metadata read error")
+	ErrMetadataParse      = errors.New("metadata file parse error")
+	ErrMetadataSave       = errors.New("metadata file save error")
 	ErrWallParse          = errors.New("wall file parse error")
 	ErrWallSave           = errors.New("wall file save error")
 	ErrTilesetParse       = errors.New("tileset file parse error")
@@ -24,6 +32,7 @@ var (
 	ErrResourceNotFound   = errors.New("resource not found")
 	ErrPackageNotUnpacked = errors.New("package not loaded in unpacked mode")
 	ErrPackageNotPacked   = errors.New("package not loaded in packed mode")
+	ErrPackageZipped      = errors.New("package loaded from a zip, changes can not be saved to it")
 	ErrReadUnpacked       = errors.New("unpacked resource read error")
 	ErrReadPacked         = errors.New("packed resource read error")
 	ErrJSONStandardize    = errors.New("error standardizing json, while trailing commas are supported the file must otherwise be valid json")
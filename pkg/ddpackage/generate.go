@@ -13,6 +13,7 @@ import (
 	"github.com/ryex/dungeondraft-gopackager/internal/utils"
 	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
 	"github.com/sirupsen/logrus"
+	"github.com/tailscale/hujson"
 )
 
 func GenPackID() string {
@@ -101,18 +102,31 @@ func SavePackageJSON(log logrus.FieldLogger, options SavePackageJSONOptions, ove
 		options.ID = GenPackID()
 	}
 
-	pack := structures.PackageInfo{
-		Name:           options.Name,
-		ID:             options.ID,
-		Author:         options.Author,
-		Version:        options.Version,
-		Keywords:       options.Keywords,
-		KeywordsRaw:    strings.Join(options.Keywords, ","),
-		Allow3rdParty:  options.Allow3rdParty,
-		ColorOverrides: options.ColorOverides,
+	var pack structures.PackageInfo
+	if packExists {
+		// start from the existing pack.json so the fields the options don't cover are kept
+		existingBytes, readErr := os.ReadFile(packJSONPath)
+		if readErr == nil {
+			existingBytes, readErr = hujson.Standardize(existingBytes)
+		}
+		if readErr == nil {
+			readErr = json.Unmarshal(existingBytes, &pack)
+		}
+		if readErr != nil {
+			log.WithError(readErr).WithField("packJSONPath", packJSONPath).Warn("can't read existing pack.json, replacing it")
+			pack = structures.PackageInfo{}
+		}
 	}
-
-	packJSONBytes, err := json.MarshalIndent(&pack, "", "  ")
+	pack.Name = options.Name
+	pack.ID = options.ID
+	pack.Author = options.Author
+	pack.Version = options.Version
+	pack.Keywords = options.Keywords
+	pack.KeywordsRaw = strings.Join(options.Keywords, ",")
+	pack.Allow3rdParty = options.Allow3rdParty
+	pack.ColorOverrides = options.ColorOverides
+
+	packJSONBytes, err := marshalPatchedJSON(packJSONPath, &pack)
 	if err != nil {
 		log.WithError(err).
 			WithField("path", folderPath).
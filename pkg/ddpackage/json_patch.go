@@ -0,0 +1,214 @@
+package ddpackage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tailscale/hujson"
+)
+
+// jsonPatchOp is an RFC 6902 patch operation as applied by hujson
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// jsonMemberAdd is a member to add to an object, added by hand to follow the layout of the object
+type jsonMemberAdd struct {
+	// json pointer to the object
+	Parent string
+	Key    string
+	Value  any
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// marshalPatchedJSON marshals v as indented json. if path already holds a json or hujson object
+// only the changed values are patched into it, keeping its comments, layout, and key order
+func marshalPatchedJSON(path string, v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return data, nil
+	}
+	patched, err := patchHuJSON(original, data)
+	if err != nil {
+		// the existing file is not usable, replace it
+		return data, nil
+	}
+	return patched, nil
+}
+
+// patchHuJSON applies the differences between the original and updated json objects to the original hujson
+func patchHuJSON(original []byte, updated []byte) ([]byte, error) {
+	ast, err := hujson.Parse(original)
+	if err != nil {
+		return nil, err
+	}
+	standard := ast.Clone()
+	standard.Standardize()
+
+	oldValue, err := decodeJSONNumbers(standard.Pack())
+	if err != nil {
+		return nil, err
+	}
+	newValue, err := decodeJSONNumbers(updated)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := oldValue.(map[string]any); !ok {
+		return updated, nil
+	}
+	if _, ok := newValue.(map[string]any); !ok {
+		return updated, nil
+	}
+
+	var ops []jsonPatchOp
+	var adds []jsonMemberAdd
+	err = diffJSON("", oldValue, newValue, &ops, &adds)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 && len(adds) == 0 {
+		return original, nil
+	}
+	if len(ops) > 0 {
+		patch, err := json.Marshal(ops)
+		if err != nil {
+			return nil, err
+		}
+		err = ast.Patch(patch)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, add := range adds {
+		err = addHuJSONMember(&ast, add)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ast.Pack(), nil
+}
+
+// addHuJSONMember appends a member to an object, on its own line with the indent of the last member
+func addHuJSONMember(ast *hujson.Value, add jsonMemberAdd) error {
+	parent := ast.Find(add.Parent)
+	if parent == nil {
+		return fmt.Errorf("no value at %q", add.Parent)
+	}
+	obj, ok := parent.Value.(*hujson.Object)
+	if !ok {
+		return fmt.Errorf("value at %q is not an object", add.Parent)
+	}
+
+	indent := []byte("  ")
+	trailingComma := false
+	if len(obj.Members) > 0 {
+		last := obj.Members[len(obj.Members)-1]
+		before := last.Name.BeforeExtra
+		indent = before[bytes.LastIndexByte(before, '\n')+1:]
+		trailingComma = last.Value.AfterExtra != nil
+	}
+
+	valueBytes, err := json.MarshalIndent(add.Value, string(indent), "  ")
+	if err != nil {
+		return err
+	}
+	value, err := hujson.Parse(valueBytes)
+	if err != nil {
+		return err
+	}
+	value.BeforeExtra = hujson.Extra(" ")
+	if trailingComma {
+		value.AfterExtra = hujson.Extra{}
+	}
+	obj.Members = append(obj.Members, hujson.ObjectMember{
+		Name: hujson.Value{
+			BeforeExtra: append(hujson.Extra("\n"), indent...),
+			Value:       hujson.String(add.Key),
+		},
+		Value: value,
+	})
+	return nil
+}
+
+func decodeJSONNumbers(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// keep numbers as written so unchanged values compare equal
+	dec.UseNumber()
+	var v any
+	err := dec.Decode(&v)
+	return v, err
+}
+
+// diffJSON collects the operations to turn oldValue into newValue, objects are patched member by member
+func diffJSON(path string, oldValue any, newValue any, ops *[]jsonPatchOp, adds *[]jsonMemberAdd) error {
+	oldObj, oldIsObj := oldValue.(map[string]any)
+	newObj, newIsObj := newValue.(map[string]any)
+	if oldIsObj && newIsObj {
+		for _, key := range slices.Sorted(maps.Keys(oldObj)) {
+			if _, ok := newObj[key]; !ok {
+				*ops = append(*ops, jsonPatchOp{Op: "remove", Path: path + "/" + jsonPointerEscaper.Replace(key)})
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(newObj)) {
+			memberPath := path + "/" + jsonPointerEscaper.Replace(key)
+			oldMember, ok := oldObj[key]
+			if !ok {
+				*adds = append(*adds, jsonMemberAdd{Parent: path, Key: key, Value: newObj[key]})
+				continue
+			}
+			err := diffJSON(memberPath, oldMember, newObj[key], ops, adds)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if jsonEqual(oldValue, newValue) {
+		return nil
+	}
+	value, err := json.Marshal(newValue)
+	if err != nil {
+		return err
+	}
+	*ops = append(*ops, jsonPatchOp{Op: "replace", Path: path, Value: value})
+	return nil
+}
+
+// jsonEqual compares decoded json, numbers are equal if they have the same value however they are written
+func jsonEqual(a any, b any) bool {
+	aNum, aIsNum := a.(json.Number)
+	bNum, bIsNum := b.(json.Number)
+	if aIsNum && bIsNum {
+		aFloat, aErr := aNum.Float64()
+		bFloat, bErr := bNum.Float64()
+		if aErr == nil && bErr == nil {
+			return aFloat == bFloat
+		}
+		return aNum == bNum
+	}
+	aArr, aIsArr := a.([]any)
+	bArr, bIsArr := b.([]any)
+	if aIsArr && bIsArr {
+		return slices.EqualFunc(aArr, bArr, jsonEqual)
+	}
+	aObj, aIsObj := a.(map[string]any)
+	bObj, bIsObj := b.(map[string]any)
+	if aIsObj && bIsObj {
+		return maps.EqualFunc(aObj, bObj, jsonEqual)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -372,6 +372,11 @@ func (p *Package) readUnpackedFileFromPackage(info *structures.FileInfo) ([]byte
 		WithField("res", info.ResPath).
 		WithField("unpackedPath", info.Path)
 
+	// images converted to png are packed from the converted data
+	if info.PngImage != nil {
+		return info.PngImage, nil
+	}
+
 	fileData, err := os.ReadFile(info.Path)
 	if err != nil {
 		l.WithError(err).Error("failed to read unpacked resource")
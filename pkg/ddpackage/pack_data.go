@@ -63,7 +63,8 @@ func (p *Package) loadPackedResourceMetadata(r io.ReadSeeker) error {
 
 	for _, fi := range p.fileList {
 
-		if !fi.IsWallData() && !fi.IsTilesetData() {
+		kind := fi.MetadataKind()
+		if kind == nil {
 			continue
 		}
 
@@ -73,35 +74,67 @@ func (p *Package) loadPackedResourceMetadata(r io.ReadSeeker) error {
 			return errors.Join(err, ErrMetadataRead, fmt.Errorf("failed to read data file %s", fi.ResPath))
 		}
 
+		err = p.parseResourceMetadata(fi, kind, fileData, fi.ResPath)
+		if err != nil {
+			return err
+		}
+	}
 
-	  fileData, err = hujson.Standardize(fileData)
-	  if err != nil {
-		  p.log.WithError(err).WithField("res", fi.ResPath).Error("failed to parse metadata json")
-		  return errors.Join(err, ErrJSONStandardize)
-	  }
+	return nil
+}
 
-		if fi.IsWallData() {
-			wall := structures.NewPackageWall()
-			err = json.Unmarshal(fileData, wall)
-			if err != nil {
-				p.log.WithError(err).WithField("res", fi.ResPath).Error("failed to parse data file")
-				return errors.Join(err, ErrWallParse, fmt.Errorf("failed to parse data file %s", fi.ResPath))
-			}
-			p.walls[fi.ResPath] = *wall
-		} else if fi.IsTilesetData() {
-			ts := structures.NewPackageTileset()
-			err = json.Unmarshal(fileData, ts)
-			if err != nil {
-				p.log.WithError(err).WithField("res", fi.ResPath).Error("failed to parse data file")
-				return errors.Join(err, ErrTilesetParse, fmt.Errorf("failed to parse data file %s", fi.ResPath))
-			}
-			p.tilesets[fi.ResPath] = *ts
+// parseResourceMetadata unmarshals a metadata file and stores it with the rest of the metadata of its kind
+func (p *Package) parseResourceMetadata(
+	fi *structures.FileInfo,
+	kind *structures.ResourceKind,
+	fileData []byte,
+	source string,
+) error {
+	fileData, err := hujson.Standardize(fileData)
+	if err != nil {
+		p.log.WithError(err).WithField("res", fi.ResPath).Error("failed to parse metadata json")
+		return errors.Join(err, ErrJSONStandardize)
+	}
+
+	value := kind.Metadata.New()
+	err = json.Unmarshal(fileData, value)
+	if err != nil {
+		p.log.WithError(err).WithField("res", fi.ResPath).Error("failed to parse data file")
+		var kindErr error
+		switch kind.Name {
+		case structures.KindWall:
+			kindErr = ErrWallParse
+		case structures.KindTileset:
+			kindErr = ErrTilesetParse
+		default:
+			kindErr = ErrMetadataParse
 		}
+		return errors.Join(err, kindErr, fmt.Errorf("failed to parse data file %s", source))
 	}
 
+	switch v := value.(type) {
+	case *structures.PackageWall:
+		p.walls[fi.ResPath] = *v
+	case *structures.PackageTileset:
+		p.tilesets[fi.ResPath] = *v
+	default:
+		p.customMetadata[fi.ResPath] = value
+	}
 	return nil
 }
 
+// resourceMetadata returns the stored metadata for a metadata file resource path
+func (p *Package) resourceMetadata(resPath string) (any, bool) {
+	if wall, ok := p.walls[resPath]; ok {
+		return &wall, true
+	}
+	if tileset, ok := p.tilesets[resPath]; ok {
+		return &tileset, true
+	}
+	value, ok := p.customMetadata[resPath]
+	return value, ok
+}
+
 func (p *Package) loadUnpackedTags() error {
 	if p.unpackedPath == "" {
 		return ErrUnsetUnpackedPath
@@ -143,6 +176,9 @@ func (p *Package) SaveUnpackedWall(resPath string) error {
 	if p.mode != PackageModeUnpacked {
 		return ErrPackageNotUnpacked
 	}
+	if p.Zipped() {
+		return ErrPackageZipped
+	}
 
 	data, ok := p.walls[resPath]
 	if !ok {
@@ -156,7 +192,7 @@ func (p *Package) SaveUnpackedWall(resPath string) error {
 	l := p.log.WithField("res", wallDataPath)
 	l.Info("saving wall")
 
-	wallBytes, err := json.MarshalIndent(&data, "", "  ")
+	wallBytes, err := marshalPatchedJSON(wallDataPath, &data)
 	if err != nil {
 		l.WithError(err).Error("can't save wall data")
 		return errors.Join(err, ErrWallSave)
@@ -185,6 +221,9 @@ func (p *Package) SaveUnpackedTileset(resPath string) error {
 	if p.mode != PackageModeUnpacked {
 		return ErrPackageNotUnpacked
 	}
+	if p.Zipped() {
+		return ErrPackageZipped
+	}
 
 	data, ok := p.tilesets[resPath]
 	if !ok {
@@ -196,7 +235,7 @@ func (p *Package) SaveUnpackedTileset(resPath string) error {
 	l := p.log.WithField("res", tilesetDataPath)
 	l.Info("saving tileset")
 
-	tilesetBytes, err := json.MarshalIndent(&data, "", "  ")
+	tilesetBytes, err := marshalPatchedJSON(tilesetDataPath, &data)
 	if err != nil {
 		l.WithError(err).Error("can't save wall data")
 		return errors.Join(err, ErrTilesetSave)
@@ -228,7 +267,8 @@ func (p *Package) loadUnpackedResourceMetadata() error {
 
 	for _, fi := range p.fileList {
 
-		if !fi.IsWallData() && !fi.IsTilesetData() {
+		kind := fi.MetadataKind()
+		if kind == nil {
 			continue
 		}
 
@@ -238,30 +278,54 @@ func (p *Package) loadUnpackedResourceMetadata() error {
 			return errors.Join(err, ErrMetadataRead, fmt.Errorf("failed to read data file %s", fi.Path))
 		}
 
+		err = p.parseResourceMetadata(fi, kind, fileData, fi.Path)
+		if err != nil {
+			return err
+		}
+	}
 
-	  fileData, err = hujson.Standardize(fileData)
-	  if err != nil {
-		  p.log.WithError(err).WithField("res", fi.ResPath).Error("failed to parse metadata json")
-		  return errors.Join(err, ErrJSONStandardize)
-	  }
+	return nil
+}
 
-		if fi.IsWallData() {
-			wall := structures.NewPackageWall()
-			err = json.Unmarshal(fileData, wall)
-			if err != nil {
-				p.log.WithError(err).WithField("res", fi.ResPath).Error("failed to parse data file")
-				return errors.Join(err, ErrWallParse, fmt.Errorf("failed to parse data file %s", fi.Path))
-			}
-			p.walls[fi.ResPath] = *wall
-		} else if fi.IsTilesetData() {
-			ts := structures.NewPackageTileset()
-			err = json.Unmarshal(fileData, ts)
-			if err != nil {
-				p.log.WithError(err).WithField("res", fi.ResPath).Error("failed to parse data file")
-				return errors.Join(err, ErrTilesetParse, fmt.Errorf("failed to parse data file %s", fi.Path))
-			}
-			p.tilesets[fi.ResPath] = *ts
-		}
+// SaveUnpackedMetadata saves the stored metadata for a metadata file resource path of any resource kind
+func (p *Package) SaveUnpackedMetadata(resPath string) error {
+	if p.mode != PackageModeUnpacked {
+		return ErrPackageNotUnpacked
+	}
+	if p.Zipped() {
+		return ErrPackageZipped
+	}
+
+	data, ok := p.resourceMetadata(resPath)
+	if !ok {
+		return nil
+	}
+
+	metadataPath := filepath.Join(p.unpackedPath, utils.NormalizeResourcePath(resPath))
+
+	l := p.log.WithField("res", metadataPath)
+	l.Info("saving metadata")
+
+	metadataBytes, err := marshalPatchedJSON(metadataPath, data)
+	if err != nil {
+		l.WithError(err).Error("can't save metadata")
+		return errors.Join(err, ErrMetadataSave)
+	}
+
+	err = os.MkdirAll(filepath.Dir(metadataPath), 0o777)
+	if err != nil {
+		l.WithError(err).Error("can't save metadata")
+		return errors.Join(err, ErrMetadataSave)
+	}
+
+	err = os.WriteFile(metadataPath, metadataBytes, 0o644)
+	if err != nil {
+		l.WithError(err).Error("can't save metadata")
+		return errors.Join(err, ErrMetadataSave)
+	}
+	errs := p.updateFromPaths([]string{metadataPath}, nil)
+	if len(errs) != 0 {
+		return errors.Join(errs...)
 	}
 
 	return nil
@@ -271,10 +335,13 @@ func (p *Package) SaveUnpackedInfo() error {
 	if p.unpackedPath == "" {
 		return ErrUnsetUnpackedPath
 	}
+	if p.Zipped() {
+		return ErrPackageZipped
+	}
 
 	packJSONPath := filepath.Join(p.unpackedPath, `pack.json`)
 
-	packJSONBytes, err := json.MarshalIndent(&p.info, "", "  ")
+	packJSONBytes, err := marshalPatchedJSON(packJSONPath, &p.info)
 	if err != nil {
 		p.log.WithError(err).
 			Error("failed to create pack json")
@@ -293,6 +360,9 @@ func (p *Package) SaveUnpackedTags() error {
 	if p.unpackedPath == "" {
 		return ErrUnsetUnpackedPath
 	}
+	if p.Zipped() {
+		return ErrPackageZipped
+	}
 
 	tagsPath := filepath.Join(p.unpackedPath, "data", "default.dungeondraft_tags")
 	dirPath := filepath.Dir(tagsPath)
@@ -329,10 +399,14 @@ func (p *Package) WriteResourceMetadata() error {
 	if p.unpackedPath == "" {
 		return ErrUnsetUnpackedPath
 	}
+	if p.Zipped() {
+		return ErrPackageZipped
+	}
 
 	for _, fi := range p.fileList {
 
-		if !fi.IsWallData() && !fi.IsTilesetData() {
+		kind := fi.MetadataKind()
+		if kind == nil {
 			continue
 		}
 
@@ -345,26 +419,17 @@ func (p *Package) WriteResourceMetadata() error {
 			}
 		}
 
-		var fileBytes []byte
-		var err error
-		if fi.IsWallData() {
-			wall := p.walls[fi.ResPath]
-			fileBytes, err = json.MarshalIndent(&wall, "", "  ")
-			if err != nil {
-				p.log.WithError(err).
-					WithField("res", fi.ResPath).
-					Error("failed to create wall json")
-				return errors.Join(err, fmt.Errorf("failed to create wall json for %s", fi.ResPath))
-			}
-		} else {
-			tileset := p.tilesets[fi.ResPath]
-			fileBytes, err = json.MarshalIndent(&tileset, "", "  ")
-			if err != nil {
-				p.log.WithError(err).
-					WithField("res", fi.ResPath).
-					Error("failed to create tileset json")
-				return errors.Join(err, fmt.Errorf("failed to create tileset json for %s", fi.ResPath))
-			}
+		data, ok := p.resourceMetadata(fi.ResPath)
+		if !ok {
+			data = kind.Metadata.New()
+		}
+		fileBytes, err := marshalPatchedJSON(fi.Path, data)
+		if err != nil {
+			p.log.WithError(err).
+				WithField("res", fi.ResPath).
+				WithField("kind", kind.Name).
+				Error("failed to create metadata json")
+			return errors.Join(err, fmt.Errorf("failed to create %s metadata json for %s", kind.Name, fi.ResPath))
 		}
 
 		if len(fileBytes) > 0 {
@@ -0,0 +1,153 @@
+package ddpackage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+)
+
+// BumpVersion increments a pack version in the `major[.minor]` format used by pack.json.
+// bumping the major version resets the minor version, a minor version of 0 is omitted
+func BumpVersion(version string, major bool) (string, error) {
+	majorStr, minorStr := utils.SplitOne(utils.TruncateToNumericString(strings.TrimSpace(version)), ".")
+	majorVer, minorVer := 0, 0
+	var err error
+	if majorStr != "" {
+		majorVer, err = strconv.Atoi(majorStr)
+		if err != nil {
+			return "", errors.Join(err, fmt.Errorf("invalid version %q", version))
+		}
+	}
+	if minorStr != "" {
+		minorVer, err = strconv.Atoi(minorStr)
+		if err != nil {
+			return "", errors.Join(err, fmt.Errorf("invalid version %q", version))
+		}
+	}
+
+	if major {
+		majorVer += 1
+		minorVer = 0
+	} else {
+		minorVer += 1
+	}
+
+	if minorVer > 0 {
+		return fmt.Sprintf("%d.%d", majorVer, minorVer), nil
+	}
+	return strconv.Itoa(majorVer), nil
+}
+
+// ChangelogEntry renders a Markdown changelog section for a release.
+// a nil diff is treated as the first release of the package
+func ChangelogEntry(version string, date time.Time, diff *PackageDiff) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s - %s\n\n", version, date.Format(time.DateOnly))
+
+	if diff == nil {
+		sb.WriteString("Initial release.\n")
+		return sb.String()
+	}
+	if diff.Empty() {
+		sb.WriteString("No changes to assets or tags.\n")
+		return sb.String()
+	}
+
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		fmt.Fprintf(&sb, "### %s\n\n", title)
+		for _, item := range items {
+			fmt.Fprintf(&sb, "- %s\n", item)
+		}
+		sb.WriteString("\n")
+	}
+
+	code := func(items []string) []string {
+		return slices.Collect(utils.Map(slices.Values(items), func(s string) string { return "`" + s + "`" }))
+	}
+
+	writeList("Added", code(diff.Added))
+	writeList("Changed", code(diff.Changed))
+	writeList("Removed", code(diff.Removed))
+	writeList("Moved", slices.Collect(utils.Map(slices.Values(diff.Moved), func(m ResourceMove) string {
+		return fmt.Sprintf("`%s` → `%s`", m.From, m.To)
+	})))
+
+	if diff.Tags != nil && !diff.Tags.Empty() {
+		var tagLines []string
+		for _, tag := range diff.Tags.ChangedTags() {
+			var parts []string
+			if slices.Contains(diff.Tags.AddedTags, tag) {
+				parts = append(parts, "new tag")
+			}
+			if slices.Contains(diff.Tags.RemovedTags, tag) {
+				parts = append(parts, "removed tag")
+			}
+			if n := len(diff.Tags.Tagged[tag]); n > 0 {
+				parts = append(parts, fmt.Sprintf("%d added", n))
+			}
+			if n := len(diff.Tags.Untagged[tag]); n > 0 {
+				parts = append(parts, fmt.Sprintf("%d removed", n))
+			}
+			tagLines = append(tagLines, fmt.Sprintf("%s: %s", tag, strings.Join(parts, ", ")))
+		}
+		writeList("Tags", tagLines)
+
+		var setLines []string
+		for _, set := range diff.Tags.ChangedSets() {
+			var parts []string
+			if slices.Contains(diff.Tags.AddedSets, set) {
+				parts = append(parts, "new set")
+			}
+			if slices.Contains(diff.Tags.RemovedSets, set) {
+				parts = append(parts, "removed set")
+			}
+			if tags := diff.Tags.SetAdded[set]; len(tags) > 0 {
+				parts = append(parts, "added "+strings.Join(tags, ", "))
+			}
+			if tags := diff.Tags.SetRemoved[set]; len(tags) > 0 {
+				parts = append(parts, "removed "+strings.Join(tags, ", "))
+			}
+			setLines = append(setLines, fmt.Sprintf("%s: %s", set, strings.Join(parts, "; ")))
+		}
+		writeList("Tag Sets", setLines)
+	}
+
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// PrependChangelogEntry inserts an entry at the top of a Markdown changelog,
+// below a leading `# ` heading if there is one. the file is created if missing
+func PrependChangelogEntry(path string, entry string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Join(err, fmt.Errorf("failed to read changelog %s", path))
+	}
+
+	content := string(existing)
+	header := "# Changelog\n\n"
+	if strings.HasPrefix(content, "# ") {
+		title, rest := utils.SplitOne(content, "\n")
+		header = title + "\n\n"
+		content = strings.TrimLeft(rest, "\n")
+	}
+
+	out := header + entry
+	if content != "" {
+		out += "\n" + content
+	}
+
+	err = os.WriteFile(path, []byte(out), 0o644)
+	if err != nil {
+		return errors.Join(err, fmt.Errorf("failed to write changelog %s", path))
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package ddpackage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+	"github.com/tailscale/hujson"
+)
+
+// TagLibraryFileName is the name of the user tag library file in the user config directory
+const TagLibraryFileName = "tag_library.jsonc"
+
+// TagLibrary is a shared vocabulary of tag sets used to seed, check, and sync the tags of packs
+type TagLibrary struct {
+	// map of set names to the tags in them
+	Sets map[string][]string `json:"sets"`
+	// map of other names for a tag to the library tag, e.g. "Chairs": "Chair"
+	Aliases map[string]string `json:"aliases"`
+
+	// map of normalized, lower case, library tags and aliases to the library tag
+	names map[string]string
+}
+
+// tagLibraryKey is how tags and aliases are compared, ignoring case and stray whitespace
+func tagLibraryKey(tag string) string {
+	return strings.ToLower(structures.NormalizeTagName(tag))
+}
+
+// ParseTagLibrary parses a tag library file, comments and trailing commas are allowed
+func ParseTagLibrary(data []byte) (*TagLibrary, error) {
+	data, err := hujson.Standardize(data)
+	if err != nil {
+		return nil, errors.Join(err, ErrJSONStandardize, ErrTagLibraryParse)
+	}
+	tl := &TagLibrary{}
+	err = json.Unmarshal(data, tl)
+	if err != nil {
+		return nil, errors.Join(err, ErrTagLibraryParse)
+	}
+
+	tl.names = make(map[string]string)
+	for _, set := range slices.Sorted(maps.Keys(tl.Sets)) {
+		for _, tag := range tl.Sets[set] {
+			key := tagLibraryKey(tag)
+			if other, ok := tl.names[key]; ok && other != tag {
+				return nil, errors.Join(ErrTagLibraryParse, fmt.Errorf("tag '%s' in set '%s' is also spelled '%s'", tag, set, other))
+			}
+			tl.names[key] = tag
+		}
+	}
+	for _, alias := range slices.Sorted(maps.Keys(tl.Aliases)) {
+		tag := tl.Aliases[alias]
+		if tl.names[tagLibraryKey(tag)] != tag {
+			return nil, errors.Join(ErrTagLibraryParse, fmt.Errorf("alias '%s' is for '%s', which is not in any set", alias, tag))
+		}
+		if other, ok := tl.names[tagLibraryKey(alias)]; ok && other != tag {
+			return nil, errors.Join(ErrTagLibraryParse, fmt.Errorf("alias '%s' for '%s' is already a name for '%s'", alias, tag, other))
+		}
+		tl.names[tagLibraryKey(alias)] = tag
+	}
+	return tl, nil
+}
+
+func LoadTagLibrary(libraryPath string) (*TagLibrary, error) {
+	data, err := os.ReadFile(libraryPath)
+	if err != nil {
+		return nil, errors.Join(err, ErrTagLibraryRead, fmt.Errorf("failed to read %s", libraryPath))
+	}
+	return ParseTagLibrary(data)
+}
+
+// DefaultTagLibraryPath is the path of the user tag library,
+// tag_library.jsonc in the dungeondraft-packager folder of the user config directory
+func DefaultTagLibraryPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "dungeondraft-packager", TagLibraryFileName), nil
+}
+
+// LibraryTag returns the library tag a tag names, by its library spelling or an alias, ignoring case
+func (tl *TagLibrary) LibraryTag(tag string) (string, bool) {
+	libraryTag, ok := tl.names[tagLibraryKey(tag)]
+	return libraryTag, ok
+}
+
+// Sync brings package tags in line with the library. tags named by an alias or spelled differently are
+// merged into the library tag, existing library tags are added to their library sets and removed from
+// library sets they are not in. with seed every library set and tag is created, even without resources,
+// CheckTags accepts tags without resources that are in a set
+func (tl *TagLibrary) Sync(pt *structures.PackageTags, seed bool) {
+	for _, tag := range slices.Sorted(maps.Keys(pt.Tags)) {
+		if libraryTag, ok := tl.LibraryTag(tag); ok && libraryTag != tag {
+			pt.MergeTags(libraryTag, tag)
+		}
+	}
+	for set, libraryTags := range tl.Sets {
+		if seed {
+			pt.AddSet(set)
+			for _, tag := range libraryTags {
+				pt.AddTag(tag)
+			}
+		}
+		for _, tag := range libraryTags {
+			if pt.TagExists(tag) {
+				pt.AddTagToSet(set, tag)
+			}
+		}
+		if setTags, ok := pt.Sets[set]; ok && setTags != nil {
+			setTags.RemoveM(tl.movedTags(set, setTags)...)
+		}
+	}
+}
+
+// movedTags are the library tags in a package set that the library set of the same name does not have
+func (tl *TagLibrary) movedTags(set string, setTags *structures.Set[string]) []string {
+	var moved []string
+	for tag := range setTags.Values() {
+		if libraryTag, ok := tl.LibraryTag(tag); ok && libraryTag == tag && !slices.Contains(tl.Sets[set], tag) {
+			moved = append(moved, tag)
+		}
+	}
+	slices.Sort(moved)
+	return moved
+}
+
+// TagLibraryCheck lists where the package tags differ from a tag library
+type TagLibraryCheck struct {
+	// package tags that are not library tags or aliases
+	Unknown []string
+	// map of package tags to the library tag they are an alias or another spelling of
+	Aliased map[string]string
+	// map of library sets to the package tags that are in them in the library but not in the package
+	MissingFromSets map[string][]string
+	// map of library sets to the library tags in them in the package that the library has in other sets
+	MovedFromSets map[string][]string
+}
+
+// Count is the number of differences found
+func (tlc *TagLibraryCheck) Count() int {
+	count := len(tlc.Unknown) + len(tlc.Aliased)
+	for _, tags := range tlc.MissingFromSets {
+		count += len(tags)
+	}
+	for _, tags := range tlc.MovedFromSets {
+		count += len(tags)
+	}
+	return count
+}
+
+// Empty reports if the package tags match the library
+func (tlc *TagLibraryCheck) Empty() bool {
+	return tlc.Count() == 0
+}
+
+// CheckTagLibrary compares the package tags with a tag library
+func (p *Package) CheckTagLibrary(tl *TagLibrary) *TagLibraryCheck {
+	tags := &p.tags
+	check := &TagLibraryCheck{
+		Aliased:         make(map[string]string),
+		MissingFromSets: make(map[string][]string),
+		MovedFromSets:   make(map[string][]string),
+	}
+	for _, tag := range slices.Sorted(maps.Keys(tags.Tags)) {
+		libraryTag, ok := tl.LibraryTag(tag)
+		if !ok {
+			check.Unknown = append(check.Unknown, tag)
+		} else if libraryTag != tag {
+			check.Aliased[tag] = libraryTag
+		}
+	}
+	for set, libraryTags := range tl.Sets {
+		setTags := tags.Sets[set]
+		for _, tag := range libraryTags {
+			if tags.TagExists(tag) && (setTags == nil || !setTags.Has(tag)) {
+				check.MissingFromSets[set] = append(check.MissingFromSets[set], tag)
+			}
+		}
+		slices.Sort(check.MissingFromSets[set])
+		if setTags != nil {
+			if moved := tl.movedTags(set, setTags); len(moved) > 0 {
+				check.MovedFromSets[set] = moved
+			}
+		}
+	}
+	return check
+}
+
+// PreviewTagLibrarySync returns the package tags synced with a tag library and the changes from the current tags,
+// the package tags are not changed
+func (p *Package) PreviewTagLibrarySync(tl *TagLibrary, seed bool) (*structures.PackageTags, *structures.TagsDiff) {
+	tags := p.tags.Clone()
+	tl.Sync(tags, seed)
+	return tags, structures.DiffPackageTags(&p.tags, tags)
+}
+
+// SyncTagLibrary syncs the package tags with a tag library and saves them if they changed
+func (p *Package) SyncTagLibrary(tl *TagLibrary, seed bool) (*structures.TagsDiff, error) {
+	tags, diff := p.PreviewTagLibrarySync(tl, seed)
+	if diff.Empty() {
+		return diff, nil
+	}
+	p.tags = *tags
+	err := p.SaveUnpackedTags()
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
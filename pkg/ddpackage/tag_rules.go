@@ -0,0 +1,205 @@
+package ddpackage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+	"github.com/tailscale/hujson"
+)
+
+// TagRulesFileName is the name of the tag rules file in the root of a package folder.
+// it is not a valid resource extension so it is never packed
+const TagRulesFileName = "tag_rules.jsonc"
+
+// TagRule tags the resources it matches
+type TagRule struct {
+	// glob patterns of relative resource paths the rule applies to, all taggable resources if empty
+	Match []string `json:"match"`
+	// glob patterns of relative resource paths the rule skips
+	Exclude []string `json:"exclude"`
+	// regular expression the file name, without its extension, must match.
+	// its captures can be used in the tags as $1 or ${name}, with no tags every non empty capture is a tag
+	Regex string `json:"regex"`
+	// tags to add
+	Tags []string `json:"tags"`
+	// sets the tags of the rule are added to
+	Sets []string `json:"sets"`
+
+	match   []*regexp.Regexp
+	exclude []*regexp.Regexp
+	regex   *regexp.Regexp
+}
+
+// TagRules are the rules of a tag rules file, applied in order
+type TagRules struct {
+	Rules []*TagRule `json:"rules"`
+	// glob patterns of relative resource paths no rule applies to
+	Exclude []string `json:"exclude"`
+
+	exclude []*regexp.Regexp
+}
+
+// matchesAny reports if a relative resource path matches any of the compiled globs
+func matchesAny(globs []*regexp.Regexp, relPath string) bool {
+	return utils.Any(slices.Values(globs), func(re *regexp.Regexp) bool {
+		return re.MatchString(relPath)
+	})
+}
+
+// ParseTagRules parses a tag rules file, comments and trailing commas are allowed
+func ParseTagRules(data []byte) (*TagRules, error) {
+	data, err := hujson.Standardize(data)
+	if err != nil {
+		return nil, errors.Join(err, ErrJSONStandardize, ErrTagRulesParse)
+	}
+	rules := &TagRules{}
+	err = json.Unmarshal(data, rules)
+	if err != nil {
+		return nil, errors.Join(err, ErrTagRulesParse)
+	}
+
+	rules.exclude, err = compileGlobs(rules.Exclude)
+	if err != nil {
+		return nil, errors.Join(err, ErrTagRulesParse)
+	}
+	for i, rule := range rules.Rules {
+		ruleErr := fmt.Errorf("rule %d", i+1)
+		rule.match, err = compileGlobs(rule.Match)
+		if err != nil {
+			return nil, errors.Join(err, ErrTagRulesParse, ruleErr)
+		}
+		rule.exclude, err = compileGlobs(rule.Exclude)
+		if err != nil {
+			return nil, errors.Join(err, ErrTagRulesParse, ruleErr)
+		}
+		if rule.Regex != "" {
+			rule.regex, err = regexp.Compile(rule.Regex)
+			if err != nil {
+				return nil, errors.Join(err, ErrTagRulesParse, ruleErr)
+			}
+		}
+		if len(rule.Tags) == 0 && (rule.regex == nil || rule.regex.NumSubexp() == 0) {
+			return nil, errors.Join(ErrTagRulesParse, ruleErr, errors.New("rule has no tags and no regex captures"))
+		}
+	}
+	return rules, nil
+}
+
+// LoadTagRules reads and parses a tag rules file
+func LoadTagRules(rulesPath string) (*TagRules, error) {
+	data, err := os.ReadFile(rulesPath)
+	if err != nil {
+		return nil, errors.Join(err, ErrTagRulesRead, fmt.Errorf("failed to read %s", rulesPath))
+	}
+	return ParseTagRules(data)
+}
+
+// TagRulesPath is the path of the tag rules file of an unpacked package
+func (p *Package) TagRulesPath() string {
+	if p.unpackedPath == "" {
+		return ""
+	}
+	return filepath.Join(p.unpackedPath, TagRulesFileName)
+}
+
+// tagsFor returns the tags the rule gives a resource and if it applies to it
+func (rule *TagRule) tagsFor(relPath string) ([]string, bool) {
+	if len(rule.match) > 0 && !matchesAny(rule.match, relPath) {
+		return nil, false
+	}
+	if matchesAny(rule.exclude, relPath) {
+		return nil, false
+	}
+	if rule.regex == nil {
+		return rule.Tags, true
+	}
+
+	name := path.Base(relPath)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	match := rule.regex.FindStringSubmatchIndex(name)
+	if match == nil {
+		return nil, false
+	}
+	var tags []string
+	if len(rule.Tags) == 0 {
+		for i := 1; i < len(match)/2; i++ {
+			if match[2*i] >= 0 {
+				tags = append(tags, name[match[2*i]:match[2*i+1]])
+			}
+		}
+	} else {
+		for _, tag := range rule.Tags {
+			tags = append(tags, string(rule.regex.ExpandString(nil, tag, name, match)))
+		}
+	}
+	return tags, true
+}
+
+// TagsFromPath returns a map of the tags the rules give a resource to the sets they should live in
+func (tr *TagRules) TagsFromPath(relPath string) map[string]*structures.Set[string] {
+	tagsMap := make(map[string]*structures.Set[string])
+	if matchesAny(tr.exclude, relPath) {
+		return tagsMap
+	}
+	for _, rule := range tr.Rules {
+		tags, ok := rule.tagsFor(relPath)
+		if !ok {
+			continue
+		}
+		for _, tag := range tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			if _, ok := tagsMap[tag]; !ok {
+				tagsMap[tag] = structures.NewSet[string]()
+			}
+			tagsMap[tag].AddM(rule.Sets...)
+		}
+	}
+	return tagsMap
+}
+
+// Apply adds the tags and sets the rules give the resources to tags.
+// applying the same rules again changes nothing
+func (tr *TagRules) Apply(tags *structures.PackageTags, relPaths []string) {
+	for _, relPath := range relPaths {
+		for tag, sets := range tr.TagsFromPath(relPath) {
+			tags.Tag(tag, relPath)
+			for set := range sets.Values() {
+				tags.AddTagToSet(set, tag)
+			}
+		}
+	}
+}
+
+// PreviewTagRules returns the package tags with the rules applied and the changes from the current tags,
+// the package tags are not changed
+func (p *Package) PreviewTagRules(rules *TagRules) (*structures.PackageTags, *structures.TagsDiff) {
+	tags := p.tags.Clone()
+	rules.Apply(tags, p.taggableRelPaths())
+	return tags, structures.DiffPackageTags(&p.tags, tags)
+}
+
+// ApplyTagRules applies the rules to the package tags and saves them if they changed
+func (p *Package) ApplyTagRules(rules *TagRules) (*structures.TagsDiff, error) {
+	tags, diff := p.PreviewTagRules(rules)
+	if diff.Empty() {
+		return diff, nil
+	}
+	p.tags = *tags
+	err := p.SaveUnpackedTags()
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
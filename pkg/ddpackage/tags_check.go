@@ -0,0 +1,194 @@
+package ddpackage
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+)
+
+// TagsCheck lists the problems found in the package tags
+type TagsCheck struct {
+	// map of tags to the resources they hold that are not in the package
+	Dangling map[string][]string
+	// map of tags to the resources they hold that are in the package but can not be tagged
+	NotTaggable map[string][]string
+	// tags without resources that are not in any set
+	EmptyTags []string
+	// map of sets to the tags in them that do not exist
+	UnknownSetTags map[string][]string
+	// sets without tags
+	EmptySets []string
+	// groups of tags that only differ by case or whitespace
+	Duplicates [][]string
+}
+
+// Count is the number of problems found
+func (tc *TagsCheck) Count() int {
+	count := len(tc.EmptyTags) + len(tc.EmptySets) + len(tc.Duplicates)
+	for _, resources := range tc.Dangling {
+		count += len(resources)
+	}
+	for _, resources := range tc.NotTaggable {
+		count += len(resources)
+	}
+	for _, tags := range tc.UnknownSetTags {
+		count += len(tags)
+	}
+	return count
+}
+
+// Empty reports if no problems were found
+func (tc *TagsCheck) Empty() bool {
+	return tc.Count() == 0
+}
+
+// resourcePaths returns the relative paths of every resource in the file list and of the taggable ones
+func (p *Package) resourcePaths() (all *structures.Set[string], taggable *structures.Set[string]) {
+	all = structures.NewSet[string]()
+	taggable = structures.NewSet[string]()
+	for _, fi := range p.FileList() {
+		relPath := utils.CleanRelativeResourcePath(fi.ResPath)
+		all.Add(relPath)
+		if fi.IsTaggable() {
+			taggable.Add(relPath)
+		}
+	}
+	return
+}
+
+// CheckTags compares the package tags with the file list
+func (p *Package) CheckTags() *TagsCheck {
+	all, taggable := p.resourcePaths()
+	tags := &p.tags
+
+	check := &TagsCheck{
+		Dangling:       make(map[string][]string),
+		NotTaggable:    make(map[string][]string),
+		UnknownSetTags: make(map[string][]string),
+	}
+	for tag, resources := range tags.Tags {
+		if resources.Size() == 0 {
+			if !inAnySet(tags, tag) {
+				check.EmptyTags = append(check.EmptyTags, tag)
+			}
+			continue
+		}
+		for resource := range resources.Values() {
+			if !all.Has(resource) {
+				check.Dangling[tag] = append(check.Dangling[tag], resource)
+			} else if !taggable.Has(resource) {
+				check.NotTaggable[tag] = append(check.NotTaggable[tag], resource)
+			}
+		}
+		slices.Sort(check.Dangling[tag])
+		slices.Sort(check.NotTaggable[tag])
+	}
+	maps.DeleteFunc(check.Dangling, func(_ string, resources []string) bool { return len(resources) == 0 })
+	maps.DeleteFunc(check.NotTaggable, func(_ string, resources []string) bool { return len(resources) == 0 })
+	slices.Sort(check.EmptyTags)
+
+	for set, setTags := range tags.Sets {
+		if setTags.Size() == 0 {
+			check.EmptySets = append(check.EmptySets, set)
+			continue
+		}
+		unknown := slices.Sorted(setTags.Filter(func(tag string) bool { return !tags.TagExists(tag) }).Values())
+		if len(unknown) > 0 {
+			check.UnknownSetTags[set] = unknown
+		}
+	}
+	slices.Sort(check.EmptySets)
+
+	check.Duplicates = duplicateTags(tags)
+
+	return check
+}
+
+// inAnySet reports if a tag is in one of the sets. tags in a set are valid without resources,
+// like the tags a tag library seeds, so they are not reported or deleted
+func inAnySet(tags *structures.PackageTags, tag string) bool {
+	for _, setTags := range tags.Sets {
+		if setTags != nil && setTags.Has(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicateTags returns the sorted groups of tags that only differ by case or whitespace
+func duplicateTags(tags *structures.PackageTags) [][]string {
+	groups := make(map[string][]string)
+	for tag := range tags.Tags {
+		key := strings.ToLower(structures.NormalizeTagName(tag))
+		groups[key] = append(groups[key], tag)
+	}
+	var duplicates [][]string
+	for _, group := range groups {
+		if len(group) > 1 {
+			slices.Sort(group)
+			duplicates = append(duplicates, group)
+		}
+	}
+	slices.SortFunc(duplicates, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return duplicates
+}
+
+// PreviewTagsFix returns the package tags with the problems CheckTags finds repaired and the changes
+// from the current tags, the package tags are not changed.
+// entries for missing or non-taggable resources are removed, duplicate tags are merged into the
+// spelling without stray whitespace that has the most resources, then tags without resources that are
+// not in any set are deleted, tags that do not exist are removed from sets, and sets left empty are deleted.
+// tags that are not duplicates are never renamed
+func (p *Package) PreviewTagsFix() (*structures.PackageTags, *structures.TagsDiff) {
+	_, taggable := p.resourcePaths()
+	tags := p.tags.Clone()
+
+	for _, resources := range tags.Tags {
+		resources.RemoveM(resources.Filter(func(resource string) bool { return !taggable.Has(resource) }).AsSlice()...)
+	}
+	normal := func(tag string) bool { return tag == structures.NormalizeTagName(tag) }
+	for _, group := range duplicateTags(tags) {
+		// spellings without stray whitespace first, then most resources, then by name
+		slices.SortFunc(group, func(a, b string) int {
+			if normal(a) != normal(b) {
+				if normal(a) {
+					return -1
+				}
+				return 1
+			}
+			return cmp.Or(cmp.Compare(tags.Tags[b].Size(), tags.Tags[a].Size()), cmp.Compare(a, b))
+		})
+		tags.MergeTags(group[0], group[1:]...)
+	}
+	for tag, resources := range tags.Tags {
+		if resources.Size() == 0 && !inAnySet(tags, tag) {
+			tags.DeleteTag(tag)
+		}
+	}
+	for set, setTags := range tags.Sets {
+		setTags.RemoveM(setTags.Filter(func(tag string) bool { return !tags.TagExists(tag) }).AsSlice()...)
+		if setTags.Size() == 0 {
+			tags.DeleteSet(set)
+		}
+	}
+
+	return tags, structures.DiffPackageTags(&p.tags, tags)
+}
+
+// FixTags repairs the problems CheckTags finds and saves the tags if they changed
+func (p *Package) FixTags() (*structures.TagsDiff, error) {
+	tags, diff := p.PreviewTagsFix()
+	if diff.Empty() {
+		return diff, nil
+	}
+	p.tags = *tags
+	err := p.SaveUnpackedTags()
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
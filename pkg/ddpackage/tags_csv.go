@@ -0,0 +1,217 @@
+package ddpackage
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+)
+
+// TagsImportMode selects how the tags in a csv are combined with the existing tags
+type TagsImportMode string
+
+const (
+	// add the tags and set memberships in the csv to the existing ones
+	TagsImportMerge TagsImportMode = "merge"
+	// the resources in the csv get exactly the tags listed for them,
+	// and the tags in the csv exactly the sets listed for them
+	TagsImportReplace TagsImportMode = "replace"
+)
+
+// columns of a tags csv. tags are separated by ';', sets are listed per tag as tag=set|set;tag=set
+var tagsCSVHeader = []string{"path", "tags", "sets"}
+
+// TagsImport is a tags csv read against a package, applied with ApplyTagsImport
+type TagsImport struct {
+	// the package tags after the import
+	Tags *structures.PackageTags
+	// paths in the csv that are not taggable resources of the package, these rows are ignored
+	UnknownPaths []string
+	// the changes the import makes to the current tags
+	Diff *structures.TagsDiff
+}
+
+// taggableRelPaths returns the relative paths of the taggable resources, as they are stored in the tags
+func (p *Package) taggableRelPaths() []string {
+	var relPaths []string
+	for _, fi := range p.FileList() {
+		if fi.IsTaggable() {
+			relPaths = append(relPaths, utils.CleanRelativeResourcePath(fi.ResPath))
+		}
+	}
+	slices.Sort(relPaths)
+	return relPaths
+}
+
+// WriteTagsCSV writes a row for every taggable resource with its tags and the sets those tags belong to
+func (p *Package) WriteTagsCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(tagsCSVHeader)
+	if err != nil {
+		return errors.Join(err, ErrTagsWrite)
+	}
+
+	for _, relPath := range p.taggableRelPaths() {
+		tags := slices.Sorted(p.tags.TagsFor(relPath).Values())
+		var sets []string
+		for _, tag := range tags {
+			var tagSets []string
+			for set, setTags := range p.tags.Sets {
+				if setTags.Has(tag) {
+					tagSets = append(tagSets, set)
+				}
+			}
+			if len(tagSets) > 0 {
+				slices.Sort(tagSets)
+				sets = append(sets, tag+"="+strings.Join(tagSets, "|"))
+			}
+		}
+		err = cw.Write([]string{relPath, strings.Join(tags, ";"), strings.Join(sets, ";")})
+		if err != nil {
+			return errors.Join(err, ErrTagsWrite)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return errors.Join(err, ErrTagsWrite)
+	}
+	return nil
+}
+
+// splitTagsCell splits a ';' separated csv cell, dropping empty entries
+func splitTagsCell(cell string) []string {
+	var values []string
+	for _, value := range strings.Split(cell, ";") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ReadTagsCSV reads a tags csv as written by WriteTagsCSV and works out the tags after importing it.
+// the package tags are not changed
+func (p *Package) ReadTagsCSV(r io.Reader, mode TagsImportMode) (*TagsImport, error) {
+	if mode == "" {
+		mode = TagsImportMerge
+	}
+	if mode != TagsImportMerge && mode != TagsImportReplace {
+		return nil, fmt.Errorf("unknown tags import mode %q", mode)
+	}
+
+	taggable := structures.SetFrom(p.taggableRelPaths())
+	unknown := structures.NewSet[string]()
+	// tags of each resource and sets of each tag listed in the csv
+	resourceTags := make(map[string]*structures.Set[string])
+	tagSets := make(map[string]*structures.Set[string])
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	line := 0
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line += 1
+		if err != nil {
+			return nil, errors.Join(err, ErrTagsParse, fmt.Errorf("line %d", line))
+		}
+		if line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), tagsCSVHeader[0]) {
+			continue
+		}
+		relPath := utils.CleanRelativeResourcePath(strings.TrimSpace(record[0]))
+		if relPath == "" {
+			continue
+		}
+		if !taggable.Has(relPath) {
+			unknown.Add(relPath)
+			continue
+		}
+
+		tags, ok := resourceTags[relPath]
+		if !ok {
+			tags = structures.NewSet[string]()
+			resourceTags[relPath] = tags
+		}
+		if len(record) > 1 {
+			tags.AddM(splitTagsCell(record[1])...)
+		}
+		if len(record) > 2 {
+			for _, entry := range splitTagsCell(record[2]) {
+				tag, sets, ok := strings.Cut(entry, "=")
+				if !ok {
+					return nil, errors.Join(ErrTagsParse, fmt.Errorf("line %d: set entry %q is not tag=set|set", line, entry))
+				}
+				tag = strings.TrimSpace(tag)
+				if _, ok := tagSets[tag]; !ok {
+					tagSets[tag] = structures.NewSet[string]()
+				}
+				for _, set := range strings.Split(sets, "|") {
+					if set = strings.TrimSpace(set); set != "" {
+						tagSets[tag].Add(set)
+					}
+				}
+			}
+		}
+	}
+
+	current := &p.tags
+	tags := current.Clone()
+	for _, relPath := range slices.Sorted(maps.Keys(resourceTags)) {
+		if mode == TagsImportReplace {
+			tags.ClearTagsFor(relPath)
+		}
+		for tag := range resourceTags[relPath].Values() {
+			tags.Tag(tag, relPath)
+		}
+	}
+	if mode == TagsImportReplace {
+		// every tag in the csv gets exactly the sets listed for it
+		listed := structures.NewSet[string]()
+		for _, rt := range resourceTags {
+			listed.AddM(rt.AsSlice()...)
+		}
+		for set := range tags.Sets {
+			tags.RemoveTagFromSet(set, listed.AsSlice()...)
+		}
+		// drop the tags the import left without resources
+		for tag, s := range tags.Tags {
+			if s.Size() == 0 && current.Tags[tag] != nil && current.Tags[tag].Size() > 0 {
+				tags.DeleteTag(tag)
+				for set := range tags.Sets {
+					tags.RemoveTagFromSet(set, tag)
+				}
+			}
+		}
+	}
+	for tag, sets := range tagSets {
+		if !tags.TagExists(tag) {
+			continue
+		}
+		for set := range sets.Values() {
+			tags.AddTagToSet(set, tag)
+		}
+	}
+
+	return &TagsImport{
+		Tags:         tags,
+		UnknownPaths: slices.Sorted(unknown.Values()),
+		Diff:         structures.DiffPackageTags(current, tags),
+	}, nil
+}
+
+// ApplyTagsImport replaces the package tags with the result of an import and saves them
+func (p *Package) ApplyTagsImport(ti *TagsImport) error {
+	p.tags = *ti.Tags.Clone()
+	return p.SaveUnpackedTags()
+}
@@ -0,0 +1,57 @@
+package ddpackage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+	"github.com/tailscale/hujson"
+)
+
+// ParsePackageTags parses the contents of a default.dungeondraft_tags file, an empty file has no tags
+func ParsePackageTags(data []byte) (*structures.PackageTags, error) {
+	tags := structures.NewPackageTags()
+	if len(bytes.TrimSpace(data)) == 0 {
+		return tags, nil
+	}
+	data, err := hujson.Standardize(data)
+	if err != nil {
+		return nil, errors.Join(err, ErrJSONStandardize, ErrTagsParse)
+	}
+	err = json.Unmarshal(data, tags)
+	if err != nil {
+		return nil, errors.Join(err, ErrTagsParse)
+	}
+	if tags.Tags == nil {
+		tags.Tags = make(map[string]*structures.Set[string])
+	}
+	if tags.Sets == nil {
+		tags.Sets = make(map[string]*structures.Set[string])
+	}
+	return tags, nil
+}
+
+// LoadPackageTags reads a default.dungeondraft_tags file outside of a package
+func LoadPackageTags(tagsPath string) (*structures.PackageTags, error) {
+	data, err := os.ReadFile(tagsPath)
+	if err != nil {
+		return nil, errors.Join(err, ErrTagsRead, fmt.Errorf("failed to read %s", tagsPath))
+	}
+	return ParsePackageTags(data)
+}
+
+// WritePackageTags writes tags as a default.dungeondraft_tags file, sorted the same as SaveUnpackedTags
+func WritePackageTags(tagsPath string, tags *structures.PackageTags) error {
+	data, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		return errors.Join(err, ErrTagsWrite, errors.New("failed to create tags json"))
+	}
+	err = os.WriteFile(tagsPath, data, 0o644)
+	if err != nil {
+		return errors.Join(err, ErrTagsWrite, fmt.Errorf("failed to write %s", tagsPath))
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package ddpackage
+
+import (
+	"math"
+	"path"
+	"slices"
+	"strings"
+)
+
+// TagsStats describes how the taggable resources of a package are tagged
+type TagsStats struct {
+	// number of taggable resources
+	Resources int `json:"resources"`
+	// number of taggable resources with at least one tag
+	Tagged int `json:"tagged"`
+	// percentage of taggable resources with at least one tag
+	Coverage float64 `json:"coverage"`
+	// average number of tags of the tagged resources
+	MeanTags float64 `json:"mean_tags"`
+
+	// map of tags to the number of resources they hold
+	Tags map[string]int `json:"tags"`
+	// map of sets to the number of tags in them
+	Sets map[string]int `json:"sets"`
+	// coverage of each folder holding taggable resources, sorted by folder
+	Folders []*FolderTagsStats `json:"folders"`
+	// map of folders to the resources in them without tags
+	Untagged map[string][]string `json:"untagged"`
+
+	// resources with at least this many tags are listed in ManyTags
+	ManyTagsThreshold int `json:"many_tags_threshold"`
+	// map of resources with an unusually high number of tags to their number of tags
+	ManyTags map[string]int `json:"many_tags"`
+}
+
+// FolderTagsStats is the tag coverage of the taggable resources directly in a folder
+type FolderTagsStats struct {
+	Folder    string  `json:"folder"`
+	Resources int     `json:"resources"`
+	Tagged    int     `json:"tagged"`
+	Coverage  float64 `json:"coverage"`
+}
+
+func percent(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(part) / float64(total)
+}
+
+// TagsStats reports how the taggable resources are tagged. resources with manyTags or more tags
+// are listed as having an unusually high number of tags, if manyTags is 0 the threshold is
+// two standard deviations above the mean number of tags
+func (p *Package) TagsStats(manyTags int) *TagsStats {
+	stats := &TagsStats{
+		Tags:     make(map[string]int),
+		Sets:     make(map[string]int),
+		Untagged: make(map[string][]string),
+		ManyTags: make(map[string]int),
+	}
+
+	for tag, resources := range p.tags.Tags {
+		stats.Tags[tag] = resources.Size()
+	}
+	for set, tags := range p.tags.Sets {
+		stats.Sets[set] = tags.Size()
+	}
+
+	// number of tags of each resource
+	tagCounts := make(map[string]int)
+	for _, resources := range p.tags.Tags {
+		for resource := range resources.Values() {
+			tagCounts[resource] += 1
+		}
+	}
+
+	relPaths := p.taggableRelPaths()
+	folders := make(map[string]*FolderTagsStats)
+	totalTags := 0
+	for _, relPath := range relPaths {
+		folder := path.Dir(relPath)
+		fs, ok := folders[folder]
+		if !ok {
+			fs = &FolderTagsStats{Folder: folder}
+			folders[folder] = fs
+		}
+		stats.Resources += 1
+		fs.Resources += 1
+		if count := tagCounts[relPath]; count > 0 {
+			stats.Tagged += 1
+			fs.Tagged += 1
+			totalTags += count
+		} else {
+			stats.Untagged[folder] = append(stats.Untagged[folder], relPath)
+		}
+	}
+	stats.Coverage = percent(stats.Tagged, stats.Resources)
+	for _, fs := range folders {
+		fs.Coverage = percent(fs.Tagged, fs.Resources)
+		stats.Folders = append(stats.Folders, fs)
+	}
+	slices.SortFunc(stats.Folders, func(a, b *FolderTagsStats) int { return strings.Compare(a.Folder, b.Folder) })
+
+	if stats.Tagged == 0 {
+		return stats
+	}
+	stats.MeanTags = float64(totalTags) / float64(stats.Tagged)
+	if manyTags <= 0 {
+		variance := 0.0
+		for _, relPath := range relPaths {
+			if count := tagCounts[relPath]; count > 0 {
+				variance += math.Pow(float64(count)-stats.MeanTags, 2)
+			}
+		}
+		stdDev := math.Sqrt(variance / float64(stats.Tagged))
+		manyTags = max(int(math.Ceil(stats.MeanTags+2*stdDev)), int(math.Floor(stats.MeanTags))+1)
+	}
+	stats.ManyTagsThreshold = manyTags
+	for _, relPath := range relPaths {
+		if count := tagCounts[relPath]; count >= manyTags {
+			stats.ManyTags[relPath] = count
+		}
+	}
+	return stats
+}
@@ -0,0 +1,240 @@
+package ddpackage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddimage"
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+)
+
+// TagsMatchMode is a way of matching the resources of two packages
+type TagsMatchMode string
+
+const (
+	// same path relative to the package root
+	TagsMatchPath TagsMatchMode = "path"
+	// same content, as it is stored in the package
+	TagsMatchHash TagsMatchMode = "hash"
+	// similar looking images
+	TagsMatchImage TagsMatchMode = "image"
+)
+
+// DefaultMaxImageDistance is the most bits the image hashes of two resources matched by image may differ by
+const DefaultMaxImageDistance = 6
+
+type TagsTransferOptions struct {
+	// how resources are matched, tried in order. defaults to path then hash
+	Match []TagsMatchMode
+	// most bits the image hashes of resources matched by image may differ by, DefaultMaxImageDistance if 0
+	MaxImageDistance int
+}
+
+// TagsTransfer is the tags of a source package copied onto a target package, applied with ApplyTagsTransfer
+type TagsTransfer struct {
+	// the target package tags after the transfer
+	Tags *structures.PackageTags
+	// map of target resources to the source resource they were matched with
+	Matches map[string]string
+	// map of target resources to how they were matched
+	MatchedBy map[string]TagsMatchMode
+	// tagged source resources that no target resource was matched with
+	UnmatchedSource []string
+	// taggable target resources that were not matched with a tagged source resource
+	UnmatchedTarget []string
+	// the changes the transfer makes to the target tags
+	Diff *structures.TagsDiff
+}
+
+// resourceInfos maps the relative paths of the taggable resources to their file info
+func (p *Package) resourceInfos() map[string]*structures.FileInfo {
+	infos := make(map[string]*structures.FileInfo)
+	for _, fi := range p.FileList() {
+		if fi.IsTaggable() {
+			infos[utils.CleanRelativeResourcePath(fi.ResPath)] = fi
+		}
+	}
+	return infos
+}
+
+// resourceImageHash returns the difference hash of the image of a resource
+func (p *Package) resourceImageHash(fi *structures.FileInfo) (uint64, error) {
+	data, err := p.LoadResource(fi.ResPath)
+	if err != nil {
+		return 0, err
+	}
+	img, _, err := ddimage.BytesToImage(data)
+	if err != nil {
+		return 0, errors.Join(err, fmt.Errorf("failed to read %s as an image", fi.ResPath))
+	}
+	return ddimage.DifferenceHash(img), nil
+}
+
+func (p *Package) PreviewTagsTransfer(source *Package, options TagsTransferOptions) (*TagsTransfer, error) {
+	return p.previewTagsTransfer(source, options, nil)
+}
+
+func (p *Package) PreviewTagsTransferProgress(
+	source *Package,
+	options TagsTransferOptions,
+	progressCallback func(p float64),
+) (*TagsTransfer, error) {
+	return p.previewTagsTransfer(source, options, progressCallback)
+}
+
+// previewTagsTransfer matches the taggable resources of the package with the tagged resources of the source
+// and works out the tags after copying the tags and set memberships of the matches. the package tags are not changed.
+// resources that can not be hashed or read as an image are logged and left unmatched by that mode,
+// as are flat images, which all have the same image hash whatever their colour
+func (p *Package) previewTagsTransfer(
+	source *Package,
+	options TagsTransferOptions,
+	pcb func(p float64),
+) (*TagsTransfer, error) {
+	if len(options.Match) == 0 {
+		options.Match = []TagsMatchMode{TagsMatchPath, TagsMatchHash}
+	}
+	if options.MaxImageDistance <= 0 {
+		options.MaxImageDistance = DefaultMaxImageDistance
+	}
+
+	sourceInfos := source.resourceInfos()
+	sourceTags := &source.tags
+	var tagged []string
+	for relPath := range sourceInfos {
+		if sourceTags.TagsFor(relPath).Size() > 0 {
+			tagged = append(tagged, relPath)
+		}
+	}
+	slices.Sort(tagged)
+	targetInfos := p.resourceInfos()
+	targets := p.taggableRelPaths()
+
+	tt := &TagsTransfer{
+		Matches:   make(map[string]string),
+		MatchedBy: make(map[string]TagsMatchMode),
+	}
+	unmatched := func() []string {
+		return slices.DeleteFunc(slices.Clone(targets), func(target string) bool {
+			_, ok := tt.Matches[target]
+			return ok
+		})
+	}
+
+	for i, mode := range options.Match {
+		progress := func(done int, total int) {
+			if pcb != nil && total > 0 {
+				pcb((float64(i) + float64(done)/float64(total)) / float64(len(options.Match)))
+			}
+		}
+		switch mode {
+		case TagsMatchPath:
+			isTagged := structures.SetFrom(tagged)
+			for _, target := range unmatched() {
+				if isTagged.Has(target) {
+					tt.Matches[target] = target
+					tt.MatchedBy[target] = mode
+				}
+			}
+		case TagsMatchHash:
+			byHash := make(map[string]string)
+			for j, relPath := range tagged {
+				progress(j, len(tagged)*2)
+				hash, err := source.ResourceHash(sourceInfos[relPath])
+				if err != nil {
+					source.log.WithError(err).WithField("res", relPath).Warn("failed to hash source resource, it can not be matched by hash")
+					continue
+				}
+				if _, ok := byHash[hash]; !ok {
+					byHash[hash] = relPath
+				}
+			}
+			for j, target := range unmatched() {
+				progress(len(tagged)+j, len(tagged)*2)
+				hash, err := p.ResourceHash(targetInfos[target])
+				if err != nil {
+					p.log.WithError(err).WithField("res", target).Warn("failed to hash resource, it can not be matched by hash")
+					continue
+				}
+				if relPath, ok := byHash[hash]; ok {
+					tt.Matches[target] = relPath
+					tt.MatchedBy[target] = mode
+				}
+			}
+		case TagsMatchImage:
+			sourceHashes := make(map[string]uint64)
+			for j, relPath := range tagged {
+				progress(j, len(tagged)*2)
+				hash, err := source.resourceImageHash(sourceInfos[relPath])
+				if err != nil {
+					source.log.WithError(err).WithField("res", relPath).Warn("failed to read source image, it can not be matched by image")
+					continue
+				}
+				if ddimage.FlatHash(hash) {
+					source.log.WithField("res", relPath).Info("source image is flat, it can not be matched by image")
+					continue
+				}
+				sourceHashes[relPath] = hash
+			}
+			for j, target := range unmatched() {
+				progress(len(tagged)+j, len(tagged)*2)
+				hash, err := p.resourceImageHash(targetInfos[target])
+				if err != nil {
+					p.log.WithError(err).WithField("res", target).Warn("failed to read image, it can not be matched by image")
+					continue
+				}
+				if ddimage.FlatHash(hash) {
+					p.log.WithField("res", target).Info("image is flat, it can not be matched by image")
+					continue
+				}
+				best := ""
+				bestDist := options.MaxImageDistance + 1
+				for _, relPath := range tagged {
+					sourceHash, ok := sourceHashes[relPath]
+					if !ok {
+						continue
+					}
+					if dist := ddimage.HashDistance(hash, sourceHash); dist < bestDist {
+						best = relPath
+						bestDist = dist
+					}
+				}
+				if best != "" {
+					tt.Matches[target] = best
+					tt.MatchedBy[target] = mode
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown tags match mode %q", mode)
+		}
+	}
+	tags := p.tags.Clone()
+	matchedSource := structures.NewSet[string]()
+	for target, relPath := range tt.Matches {
+		matchedSource.Add(relPath)
+		for tag := range sourceTags.TagsFor(relPath).Values() {
+			tags.Tag(tag, target)
+			for set, setTags := range sourceTags.Sets {
+				if setTags.Has(tag) {
+					tags.AddTagToSet(set, tag)
+				}
+			}
+		}
+	}
+	tt.Tags = tags
+	tt.UnmatchedSource = slices.DeleteFunc(tagged, matchedSource.Has)
+	tt.UnmatchedTarget = unmatched()
+	tt.Diff = structures.DiffPackageTags(&p.tags, tags)
+	if pcb != nil {
+		pcb(1)
+	}
+	return tt, nil
+}
+
+// ApplyTagsTransfer replaces the package tags with the result of a transfer and saves them
+func (p *Package) ApplyTagsTransfer(tt *TagsTransfer) error {
+	p.tags = *tt.Tags.Clone()
+	return p.SaveUnpackedTags()
+}
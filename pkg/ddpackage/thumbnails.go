@@ -28,6 +28,9 @@ func (p *Package) generateThumbnails(progressCallback func(p float64)) []error {
 	if p.unpackedPath == "" {
 		return []error{ErrUnsetUnpackedPath}
 	}
+	if p.Zipped() {
+		return []error{ErrPackageZipped}
+	}
 	thumbnailDir := filepath.Join(p.unpackedPath, "thumbnails")
 
 	if dirExists := utils.DirExists(thumbnailDir); !dirExists {
@@ -64,17 +67,7 @@ func (p *Package) generateThumbnails(progressCallback func(p float64)) []error {
 			img = fi.Image
 		}
 
-		var thumbnail image.Image
-
-		if fi.IsTerrain() {
-			thumbnail = ddimage.TerrainThumbnail(img)
-		} else if fi.IsWall() {
-			thumbnail = ddimage.WallThumbnail(img)
-		} else if fi.IsPath() {
-			thumbnail = ddimage.PathThumbnail(img)
-		} else {
-			thumbnail = ddimage.DefaultThumbnail(img)
-		}
+		thumbnail := fi.Kind().MakeThumbnail(img)
 
 		file, err := os.OpenFile(
 			fi.ThumbnailPath,
@@ -11,10 +11,13 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/ryex/dungeondraft-gopackager/internal/utils"
 	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+	"github.com/sirupsen/logrus"
 	"github.com/tailscale/hujson"
 )
 
@@ -45,19 +48,6 @@ func (p *Package) extractPackage(
 	p.SetUnpackOptions(options)
 	p.unpackedPath = outDir
 
-	err = p.extractFilelist(outDir, progressCallback)
-
-	return
-}
-
-func (p *Package) MapResourcePaths() {
-	for _, fi := range p.fileList {
-		fi.Path = utils.NormalizeResourcePath(fi.ResPath)
-	}
-}
-
-// extractFilelist takes a slice of FileInfo and extracts the files from the package at the reader
-func (p *Package) extractFilelist(outDir string, progressCallback func(p float64)) (err error) {
 	outDirPath, err := filepath.Abs(outDir)
 	if err != nil {
 		return
@@ -76,6 +66,87 @@ func (p *Package) extractFilelist(outDir string, progressCallback func(p float64
 		}
 	}
 
+	err = p.extractFilelist(&dirWriter{root: outDirPath}, progressCallback)
+
+	return
+}
+
+// ExtractPackageZip extracts the package contents into a zip archive.
+// the files are placed in a folder named after the package inside the archive
+func (p *Package) ExtractPackageZip(
+	zipPath string,
+	options UnpackOptions,
+) (err error) {
+	return p.extractPackageZip(zipPath, options, nil)
+}
+
+func (p *Package) ExtractPackageZipProgress(
+	zipPath string,
+	options UnpackOptions,
+	progressCallback func(p float64),
+) (err error) {
+	return p.extractPackageZip(zipPath, options, progressCallback)
+}
+
+func (p *Package) extractPackageZip(
+	zipPath string,
+	options UnpackOptions,
+	progressCallback func(p float64),
+) (err error) {
+	if p.mode != PackageModePacked {
+		return ErrPackageNotPacked
+	}
+	p.SetUnpackOptions(options)
+
+	zipPath, err = filepath.Abs(zipPath)
+	if err != nil {
+		return
+	}
+	l := p.log.WithField("zipPath", zipPath)
+
+	if utils.DirExists(zipPath) {
+		err = errors.New("out zip already exists as a folder")
+		return
+	}
+	err = os.MkdirAll(filepath.Dir(zipPath), 0o777)
+	if err != nil {
+		return
+	}
+	dw := &dirWriter{root: filepath.Dir(zipPath)}
+	if zipName := filepath.Base(zipPath); dw.Exists(zipName) {
+		var write bool
+		// a new archive is never byte identical to an old one, so there is nothing to compare against
+		zipName, write, err = p.resolveConflict(l.WithField("unpackedFile", zipName), dw, zipName, nil)
+		if err != nil || !write {
+			return
+		}
+		zipPath = filepath.Join(dw.root, zipName)
+	}
+
+	w, err := newZipWriter(zipPath, p.name)
+	if err != nil {
+		l.WithError(err).Error("can not open zip for writing")
+		return
+	}
+
+	err = p.extractFilelist(w, progressCallback)
+	err = errors.Join(err, w.Close())
+	if err != nil {
+		// don't leave a partial archive behind
+		os.Remove(zipPath)
+	}
+
+	return
+}
+
+func (p *Package) MapResourcePaths() {
+	for _, fi := range p.fileList {
+		fi.Path = utils.NormalizeResourcePath(fi.ResPath)
+	}
+}
+
+// extractFilelist extracts the files in the package file list to the writer
+func (p *Package) extractFilelist(w extractWriter, progressCallback func(p float64)) (err error) {
 	valid, err := p.isValidPackage(p.pkgFile)
 
 	if !valid {
@@ -90,6 +161,14 @@ func (p *Package) extractFilelist(outDir string, progressCallback func(p float64
 
 	p.MapResourcePaths()
 
+	var selection *extractSelection
+	if p.unpackOptions.Selective() {
+		selection, err = p.selectExtracted()
+		if err != nil {
+			return
+		}
+	}
+
 	thumbnailPrefix := fmt.Sprintf("res://packs/%s/thumbnails/", p.id)
 
 	extractedPaths := make(map[string]string)
@@ -104,6 +183,10 @@ func (p *Package) extractFilelist(outDir string, progressCallback func(p float64
 			continue
 		}
 
+		if selection != nil && !selection.keep(fi) {
+			continue
+		}
+
 		if resPath, ok := extractedPaths[fi.Path]; ok {
 			p.log.
 				WithField("packedPath", fi.ResPath).
@@ -112,7 +195,7 @@ func (p *Package) extractFilelist(outDir string, progressCallback func(p float64
 			continue
 		}
 
-		path := filepath.Join(outDirPath, filepath.Dir(fi.Path))
+		path := filepath.Dir(fi.Path)
 		p.log.WithField("mappedPath", fi.Path).Debugf("%s -> %s", fi.ResPath, path)
 
 		fileNameFull := filepath.Base(fi.ResPath)
@@ -122,21 +205,17 @@ func (p *Package) extractFilelist(outDir string, progressCallback func(p float64
 			continue
 		}
 
-		l := p.log.
-			WithField("packedPath", fi.ResPath).
-			WithField("offset", fi.Offset)
-
-		err = os.MkdirAll(path, 0o777)
-		if err != nil {
-			l.WithField("unpackedFile", path).WithError(err).
-				Error("can not make target directory")
+		if _, err = p.extractFile(w, fi, path); err != nil {
 			return err
 		}
+		extractedPaths[fi.Path] = fi.ResPath
+	}
 
-		if _, err = p.ExtractFile(fi, path); err != nil {
-			return err
+	if selection != nil && selection.tags != nil {
+		err = p.writeSelectedTags(w, selection)
+		if err != nil {
+			return
 		}
-		extractedPaths[fi.Path] = fi.ResPath
 	}
 
 	if progressCallback != nil {
@@ -148,7 +227,13 @@ func (p *Package) extractFilelist(outDir string, progressCallback func(p float64
 	return
 }
 
+// ExtractFile extracts a single resource into the outPath folder and returns the written file path
 func (p *Package) ExtractFile(info *structures.FileInfo, outPath string) (string, error) {
+	return p.extractFile(&dirWriter{root: outPath}, info, ".")
+}
+
+// extractFile extracts a single resource to the relDir folder of the writer
+func (p *Package) extractFile(w extractWriter, info *structures.FileInfo, relDir string) (string, error) {
 	l := p.log.
 		WithField("packedPath", info.ResPath).
 		WithField("offset", info.Offset)
@@ -173,38 +258,231 @@ func (p *Package) ExtractFile(info *structures.FileInfo, outPath string) (string
 		}
 	}
 
-	filePath := filepath.Join(outPath, fileNameFull)
-	l = l.WithField("unpackedFile", filePath)
+	filePath := filepath.Join(relDir, fileNameFull)
+	return p.writeExtractedFile(l, w, filePath, fileData)
+}
+
+// writeExtractedFile writes extracted data to relPath of the writer, resolving conflicts with
+// existing files by the Conflict option. returns an empty path if the file was skipped
+func (p *Package) writeExtractedFile(l logrus.FieldLogger, w extractWriter, relPath string, fileData []byte) (string, error) {
+	l = l.WithField("unpackedFile", relPath)
 
-	fileExists := utils.FileExists(filePath)
+	var err error
+	fileExists := w.Exists(relPath)
 	if fileExists {
-		if p.unpackOptions.Overwrite {
-			l.Warn("overwriting file")
-		} else {
-			err = errors.New("file exists")
-			l.WithError(err).Error("file already exists at destination and Overwrite not enabled")
+		var write bool
+		relPath, write, err = p.resolveConflict(l, w, relPath, fileData)
+		if err != nil || !write {
 			return "", err
 		}
 	}
 
-	var f *os.File
-	f, err = os.Create(filePath)
+	filePath, err := w.WriteFile(relPath, fileData)
 	if err != nil {
-		l.WithError(err).Error("can not open file for writing")
+		l.WithError(err).Error("failed to write file")
 		return "", err
 	}
-	_, err = f.Write(fileData)
+	return filePath, nil
+}
+
+// resolveConflict applies the Conflict option to an existing file at relPath.
+// returns the path to write the extracted data to and if it should be written at all.
+// a nil fileData never compares identical
+func (p *Package) resolveConflict(
+	l logrus.FieldLogger,
+	w extractWriter,
+	relPath string,
+	fileData []byte,
+) (string, bool, error) {
+	switch p.unpackOptions.Conflict {
+	case ConflictOverwrite:
+		l.Warn("overwriting file")
+		return relPath, true, nil
+	case ConflictSkip:
+		l.Info("skipping existing file")
+		return relPath, false, nil
+	case ConflictSkipIfIdentical:
+		if fileData != nil {
+			existing, err := w.ReadFile(relPath)
+			if err != nil {
+				l.WithError(err).Error("can not read existing file to compare")
+				return relPath, false, err
+			}
+			if md5.Sum(existing) == md5.Sum(fileData) {
+				l.Debug("skipping identical existing file")
+				return relPath, false, nil
+			}
+		}
+		l.Warn("overwriting changed file")
+		return relPath, true, nil
+	case ConflictRename:
+		renamed := uniquePath(w, relPath, func(base, ext string, n int) string {
+			return fmt.Sprintf("%s_%d%s", base, n, ext)
+		})
+		l.WithField("renamedFile", renamed).Warn("file exists, writing under a new name")
+		return renamed, true, nil
+	case ConflictBackup:
+		backup := uniquePath(w, relPath, func(base, ext string, n int) string {
+			if n == 1 {
+				return base + ext + ".bak"
+			}
+			return fmt.Sprintf("%s%s.bak%d", base, ext, n)
+		})
+		err := w.Rename(relPath, backup)
+		if err != nil {
+			l.WithError(err).WithField("backupFile", backup).Error("failed to back up existing file")
+			return relPath, false, err
+		}
+		l.WithField("backupFile", backup).Warn("backed up existing file")
+		return relPath, true, nil
+	case ConflictFail, "":
+		err := errors.New("file exists")
+		l.WithError(err).Error("file already exists at destination and the conflict strategy is fail")
+		return relPath, false, err
+	default:
+		err := fmt.Errorf("unknown conflict strategy %s", p.unpackOptions.Conflict)
+		l.WithError(err).Error("can not resolve conflict")
+		return relPath, false, err
+	}
+}
+
+// uniquePath returns the first name built by the name func, counting from 1, that does not exist in the writer
+func uniquePath(w extractWriter, relPath string, name func(base, ext string, n int) string) string {
+	ext := filepath.Ext(relPath)
+	base := strings.TrimSuffix(relPath, ext)
+	for n := 1; ; n++ {
+		candidate := name(base, ext, n)
+		if !w.Exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// extractSelection records the resources picked out of a package by a selective unpack
+type extractSelection struct {
+	// resource paths of the selected textures and their thumbnails and metadata
+	resources *structures.Set[string]
+	// resource path of the package tags file
+	tagsResPath string
+	// tags trimmed to the selected textures, nil if the package has no tags file
+	tags *structures.PackageTags
+}
+
+// keep reports if a resource should be extracted
+func (s *extractSelection) keep(fi *structures.FileInfo) bool {
+	if fi.ResPath == s.tagsResPath {
+		// written separately after trimming
+		return false
+	}
+	if fi.IsTexture() || fi.IsThumbnail() || fi.MetadataKind() != nil {
+		return s.resources.Has(fi.ResPath)
+	}
+	return true
+}
+
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := structures.GlobToRelPathRegexp(pattern)
+		if err != nil {
+			return nil, errors.Join(err, structures.ErrBadFileInfoListGlobPattern, fmt.Errorf("bad glob pattern %s", pattern))
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// selectExtracted picks the textures matching the unpack options
+func (p *Package) selectExtracted() (*extractSelection, error) {
+	options := p.unpackOptions
+
+	include, err := compileGlobs(options.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileGlobs(options.Exclude)
 	if err != nil {
-		l.WithError(err).Error("failed to write file")
-		return "", err
+		return nil, err
+	}
+	for _, category := range options.Categories {
+		if structures.GetResourceKind(category) == nil {
+			return nil, fmt.Errorf("unknown resource category %s", category)
+		}
 	}
 
-	err = f.Close()
+	err = p.loadPackedTags(p.pkgFile)
 	if err != nil {
-		l.WithError(err).Error("failed to close file")
-		return "", err
+		return nil, err
 	}
-	return filePath, nil
+
+	selection := &extractSelection{
+		resources:   structures.NewSet[string](),
+		tagsResPath: fmt.Sprintf("res://packs/%s/data/default.dungeondraft_tags", p.id),
+	}
+	selectedRelPaths := structures.NewSet[string]()
+
+	for _, fi := range p.fileList {
+		if !fi.IsTexture() {
+			continue
+		}
+		relPath := fi.CalcRelPath()
+		matches := func(re *regexp.Regexp) bool {
+			return re.MatchString(relPath)
+		}
+		if len(include) > 0 && !utils.Any(slices.Values(include), matches) {
+			continue
+		}
+		if utils.Any(slices.Values(exclude), matches) {
+			continue
+		}
+		if len(options.Categories) > 0 {
+			kind := fi.Kind()
+			if kind == nil || !slices.Contains(options.Categories, kind.Name) {
+				continue
+			}
+		}
+		if len(options.Tags) > 0 {
+			tags := p.tags.TagsFor(relPath)
+			if !utils.Any(slices.Values(options.Tags), tags.Has) {
+				continue
+			}
+		}
+
+		selectedRelPaths.Add(relPath)
+		selection.resources.Add(fi.ResPath)
+		if fi.ThumbnailResPath != "" {
+			selection.resources.Add(fi.ThumbnailResPath)
+		}
+		if fi.MetadataPath != "" {
+			selection.resources.Add(fi.MetadataPath)
+		}
+	}
+
+	p.log.WithField("selected", selectedRelPaths.Size()).Info("selected textures to extract")
+
+	if p.fileList.IndexOfRes(selection.tagsResPath) != -1 {
+		selection.tags = p.tags.Filter(selectedRelPaths.Has)
+	}
+
+	return selection, nil
+}
+
+// writeSelectedTags writes the trimmed tags of a selective unpack
+func (p *Package) writeSelectedTags(w extractWriter, selection *extractSelection) error {
+	l := p.log.WithField("packedPath", selection.tagsResPath)
+
+	tagsBytes, err := json.MarshalIndent(selection.tags, "", "  ")
+	if err != nil {
+		l.WithError(err).Error("failed to create tags json")
+		return errors.Join(err, ErrTagsWrite)
+	}
+
+	tagsPath := utils.NormalizeResourcePath(selection.tagsResPath)
+	_, err = p.writeExtractedFile(l, w, tagsPath, tagsBytes)
+	if err != nil {
+		return errors.Join(err, ErrTagsWrite)
+	}
+	return nil
 }
 
 func (p *Package) readPackedFileFromPackage(r io.ReadSeeker, info *structures.FileInfo) ([]byte, error) {
@@ -471,19 +749,7 @@ func (p *Package) updatePackedFileInfoAfter() {
 			fi.ThumbnailResPath = fmt.Sprintf("res://packs/%s/thumbnails/%s", p.id, thumbnailName)
 		}
 
-		isWall := fi.IsWall()
-		isTileset := fi.IsTileset()
-
-		if isWall || isTileset {
-			fName := filepath.Base(fi.RelPath)
-			bName := fName[:len(fName)-len(filepath.Ext(fName))]
-
-			if isWall {
-				fi.MetadataPath = fmt.Sprintf("res://packs/%s/data/walls/%s.dungeondraft_wall", p.id, bName)
-			} else {
-				fi.MetadataPath = fmt.Sprintf("res://packs/%s/data/tilesets/%s.dungeondraft_tileset", p.id, bName)
-			}
-		}
+		fi.MetadataPath = p.metadataResPath(fi)
 	}
 }
 
@@ -0,0 +1,209 @@
+package ddpackage
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+	"github.com/ryex/dungeondraft-gopackager/pkg/structures"
+)
+
+// extractWriter receives the files written by an extraction.
+// paths are relative to the output root and use the os separator, as returned by utils.NormalizeResourcePath
+type extractWriter interface {
+	// reports if a file was already written at the path
+	Exists(relPath string) bool
+	// writes a file and returns a description of where it was written
+	WriteFile(relPath string, data []byte) (string, error)
+	// reads back an existing file
+	ReadFile(relPath string) ([]byte, error)
+	// moves an existing file to a new path
+	Rename(fromRelPath string, toRelPath string) error
+	Close() error
+}
+
+// dirWriter writes extracted files into a folder
+type dirWriter struct {
+	root string
+}
+
+func (w *dirWriter) Exists(relPath string) bool {
+	return utils.FileExists(filepath.Join(w.root, relPath))
+}
+
+func (w *dirWriter) WriteFile(relPath string, data []byte) (string, error) {
+	filePath := filepath.Join(w.root, relPath)
+	err := os.MkdirAll(filepath.Dir(filePath), 0o777)
+	if err != nil {
+		return filePath, errors.Join(err, errors.New("can not make target directory"))
+	}
+	return filePath, os.WriteFile(filePath, data, 0o666)
+}
+
+func (w *dirWriter) ReadFile(relPath string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(w.root, relPath))
+}
+
+func (w *dirWriter) Rename(fromRelPath string, toRelPath string) error {
+	return os.Rename(filepath.Join(w.root, fromRelPath), filepath.Join(w.root, toRelPath))
+}
+
+func (w *dirWriter) Close() error {
+	return nil
+}
+
+// zipWriter writes extracted files into a zip archive under a root folder
+type zipWriter struct {
+	zipPath string
+	prefix  string
+	file    *os.File
+	zw      *zip.Writer
+	written *structures.Set[string]
+}
+
+func newZipWriter(zipPath string, rootFolder string) (*zipWriter, error) {
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	return &zipWriter{
+		zipPath: zipPath,
+		prefix:  rootFolder + "/",
+		file:    f,
+		zw:      zip.NewWriter(f),
+		written: structures.NewSet[string](),
+	}, nil
+}
+
+func (w *zipWriter) entryName(relPath string) string {
+	return w.prefix + filepath.ToSlash(relPath)
+}
+
+func (w *zipWriter) Exists(relPath string) bool {
+	return w.written.Has(w.entryName(relPath))
+}
+
+func (w *zipWriter) WriteFile(relPath string, data []byte) (string, error) {
+	name := w.entryName(relPath)
+	location := w.zipPath + ":" + name
+	fw, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	})
+	if err != nil {
+		return location, err
+	}
+	_, err = fw.Write(data)
+	if err != nil {
+		return location, err
+	}
+	w.written.Add(name)
+	return location, nil
+}
+
+// files written to a zip can not be read back or moved
+func (w *zipWriter) ReadFile(relPath string) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (w *zipWriter) Rename(fromRelPath string, toRelPath string) error {
+	return errors.ErrUnsupported
+}
+
+func (w *zipWriter) Close() error {
+	return errors.Join(w.zw.Close(), w.file.Close())
+}
+
+// IsZipPath reports if a path names a zip archive
+func IsZipPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".zip") && utils.FileExists(path)
+}
+
+// unzipPackFolder extracts a zip archive into a new temporary folder and
+// returns the temporary folder and the pack folder inside it.
+// the pack folder is the shallowest folder holding a pack.json
+func unzipPackFolder(zipPath string) (tempDir string, packDir string, err error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return "", "", errors.Join(err, fmt.Errorf("could not open zip %s", zipPath))
+	}
+	defer zr.Close()
+
+	tempDir, err = os.MkdirTemp("", "dungeondraft-pack-")
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(tempDir)
+			tempDir = ""
+		}
+	}()
+
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || strings.HasPrefix(zf.Name, "__MACOSX/") {
+			continue
+		}
+		relPath := filepath.FromSlash(strings.TrimPrefix(zf.Name, "./"))
+		if !filepath.IsLocal(relPath) {
+			return tempDir, "", fmt.Errorf("zip entry %s escapes the archive root", zf.Name)
+		}
+		err = unzipFile(zf, filepath.Join(tempDir, relPath))
+		if err != nil {
+			return tempDir, "", errors.Join(err, fmt.Errorf("could not extract %s from zip", zf.Name))
+		}
+	}
+
+	depth := -1
+	err = filepath.WalkDir(tempDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "pack.json" {
+			return nil
+		}
+		rel, _ := filepath.Rel(tempDir, filepath.Dir(path))
+		pathDepth := 0
+		if rel != "." {
+			pathDepth = len(strings.Split(rel, string(filepath.Separator)))
+		}
+		if depth == -1 || pathDepth < depth {
+			depth = pathDepth
+			packDir = filepath.Dir(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return tempDir, "", err
+	}
+	if packDir == "" {
+		return tempDir, "", errors.Join(ErrMissingPackJSON, fmt.Errorf("no pack.json in zip %s", zipPath))
+	}
+	return tempDir, packDir, nil
+}
+
+func unzipFile(zf *zip.File, filePath string) error {
+	err := os.MkdirAll(filepath.Dir(filePath), 0o777)
+	if err != nil {
+		return err
+	}
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	f, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, rc)
+	return errors.Join(err, f.Close())
+}
@@ -0,0 +1,128 @@
+package structures
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Extra keeps the json fields of an object that its typed struct does not cover so they survive a round trip.
+// it also records which of the known fields were present, so fields missing from a loaded object
+// are not written back with their zero value
+type Extra struct {
+	// unknown fields by name
+	Fields map[string]json.RawMessage
+
+	present map[string]bool
+}
+
+// jsonName returns the json field name of a struct field, or an empty string if it is skipped, and if it is omitempty
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() || f.Anonymous {
+		return "", false
+	}
+	name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, slices.Contains(strings.Split(opts, ","), "omitempty")
+}
+
+// isEmptyValue reports if encoding/json would leave out an omitempty field with the value
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// UnmarshalObject decodes a json object into v, a pointer to a struct without its own UnmarshalJSON,
+// and fills extra with the fields v does not know
+func UnmarshalObject(data []byte, v any, extra *Extra) error {
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+
+	extra.present = make(map[string]bool)
+	rt := reflect.TypeOf(v).Elem()
+	for i := 0; i < rt.NumField(); i++ {
+		name, _ := jsonName(rt.Field(i))
+		if name == "" {
+			continue
+		}
+		if _, ok := fields[name]; ok {
+			extra.present[name] = true
+			delete(fields, name)
+		}
+	}
+	extra.Fields = nil
+	if len(fields) > 0 {
+		extra.Fields = fields
+	}
+	return nil
+}
+
+// MarshalObject encodes v, a struct without its own MarshalJSON, followed by the unknown fields in extra
+func MarshalObject(v any, extra *Extra) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	first := true
+	write := func(name string, value []byte) {
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		nameJSON, _ := json.Marshal(name)
+		buf.Write(nameJSON)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	// an addressable copy so fields with pointer receiver MarshalJSON methods use them
+	rv := reflect.New(reflect.TypeOf(v)).Elem()
+	rv.Set(reflect.ValueOf(v))
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		name, omitEmpty := jsonName(rt.Field(i))
+		if name == "" {
+			continue
+		}
+		fv := rv.Field(i)
+		if omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if extra.present != nil && !extra.present[name] && fv.IsZero() {
+			continue
+		}
+		value, err := json.Marshal(fv.Addr().Interface())
+		if err != nil {
+			return nil, err
+		}
+		write(name, value)
+	}
+	for _, name := range slices.Sorted(maps.Keys(extra.Fields)) {
+		write(name, extra.Fields[name])
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -84,11 +84,27 @@ func (fi *FileInfo) IsMetadata() bool {
 }
 
 func (fi *FileInfo) ShouldHaveMetadata() bool {
-	return (fi.IsWall() &&
-		!strings.HasSuffix(
-			strings.TrimSuffix(filepath.Base(fi.ResPath), filepath.Ext(fi.ResPath)),
-			"_end",
-		)) || fi.IsTileset()
+	kind := fi.Kind()
+	if kind == nil || kind.Metadata == nil {
+		return false
+	}
+	return kind.Metadata.Required == nil || kind.Metadata.Required(fi.CalcRelPath())
+}
+
+// Kind returns the registered resource kind for the file or nil
+func (fi *FileInfo) Kind() *ResourceKind {
+	return ResourceKindFor(fi.CalcRelPath())
+}
+
+// IsKind reports if the file belongs to the resource kind with the passed name
+func (fi *FileInfo) IsKind(name string) bool {
+	kind := fi.Kind()
+	return kind != nil && kind.Name == name
+}
+
+// MetadataKind returns the resource kind the file is a metadata file for or nil
+func (fi *FileInfo) MetadataKind() *ResourceKind {
+	return MetadataKindFor(fi.CalcRelPath())
 }
 
 func (fi *FileInfo) IsData() bool {
@@ -112,51 +128,52 @@ func (fi *FileInfo) IsThumbnail() bool {
 }
 
 func (fi *FileInfo) IsCave() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/caves/")
+	return fi.IsKind(KindCave)
 }
 
 func (fi *FileInfo) IsLight() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/lights/")
+	return fi.IsKind(KindLight)
 }
 
 func (fi *FileInfo) IsMaterial() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/materials/")
+	return fi.IsKind(KindMaterial)
 }
 
 func (fi *FileInfo) IsObject() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/objects/")
+	return fi.IsKind(KindObject)
 }
 
 func (fi *FileInfo) IsPath() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/paths/")
+	return fi.IsKind(KindPath)
 }
 
 func (fi *FileInfo) IsPattern() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/patterns/")
+	return fi.IsKind(KindPattern)
 }
 
 func (fi *FileInfo) IsPortal() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/portals/")
+	return fi.IsKind(KindPortal)
 }
 
 func (fi *FileInfo) IsRoof() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/roofs/")
+	return fi.IsKind(KindRoof)
 }
 
 func (fi *FileInfo) IsTerrain() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/terrain/")
+	return fi.IsKind(KindTerrain)
 }
 
 func (fi *FileInfo) IsTileset() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/tilesets/")
+	return fi.IsKind(KindTileset)
 }
 
 func (fi *FileInfo) IsWall() bool {
-	return strings.HasPrefix(fi.CalcRelPath(), "textures/walls/")
+	return fi.IsKind(KindWall)
 }
 
 func (fi *FileInfo) IsTaggable() bool {
-	return fi.IsObject()
+	kind := fi.Kind()
+	return kind != nil && kind.Taggable
 }
 
 type FileInfoList []*FileInfo
@@ -193,10 +210,9 @@ func (fil FileInfoList) Filter(P func(fi *FileInfo) bool) FileInfoList {
 	return res
 }
 
-func (fil FileInfoList) Remove(i int) *FileInfo {
-	res := fil[i]
-	fil[i] = fil[len(fil)-1]
-	fil = fil[:len(fil)-1]
+func (fil *FileInfoList) Remove(i int) *FileInfo {
+	res := (*fil)[i]
+	*fil = slices.Delete(*fil, i, i+1)
 	return res
 }
 
@@ -218,7 +234,7 @@ func (fil FileInfoList) IndexOfRes(res string) int {
 	return -1
 }
 
-func (fil FileInfoList) RemoveRes(res string) *FileInfo {
+func (fil *FileInfoList) RemoveRes(res string) *FileInfo {
 	index := fil.IndexOfRes(res)
 	if index != -1 {
 		return fil.Remove(index)
@@ -330,15 +346,15 @@ func (fil FileInfoList) SetCapacity(capacity int) {
 	}
 }
 
-func (fil FileInfoList) UpdateThumbnailRefrences() {
+func (fil *FileInfoList) UpdateThumbnailRefrences() {
 	thumbnailMap := make(map[string]string)
-	for _, fi := range fil {
+	for _, fi := range *fil {
 		if fi.IsTexture() && fi.ThumbnailPath != "" {
 			thumbnailMap[fi.ThumbnailResPath] = fi.ResPath
 		}
 	}
 	toRemove := NewSet[string]()
-	for _, fi := range fil {
+	for _, fi := range *fil {
 		if fi.IsThumbnail() {
 			forRes, ok := thumbnailMap[fi.ResPath]
 			if ok {
@@ -391,10 +407,10 @@ func cmpResAndThumb(a, b *FileInfo) int {
 }
 
 // places the file list
-func (fil FileInfoList) Sort() {
+func (fil *FileInfoList) Sort() {
 	fil.UpdateThumbnailRefrences()
 
-	slices.SortFunc(fil, func(a, b *FileInfo) int {
+	slices.SortFunc(*fil, func(a, b *FileInfo) int {
 		return cmpResPaths(a.ResPath, b.ResPath)
 	})
 }
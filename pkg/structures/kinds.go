@@ -0,0 +1,166 @@
+package structures
+
+import (
+	"image"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/ryex/dungeondraft-gopackager/pkg/ddimage"
+)
+
+// MetadataKind describes the metadata file that accompanies resources of a kind
+type MetadataKind struct {
+	// folder relative to the package root that holds the metadata files, e.g. "data/walls"
+	Dir string
+	// extension of the metadata files, e.g. ".dungeondraft_wall"
+	Ext string
+	// returns a pointer to a new value, with defaults set, to unmarshal a metadata file into
+	New func() any
+	// reports if the resource at the relative path needs a metadata file, nil means always
+	Required func(relPath string) bool
+}
+
+// MetadataPath returns the path, relative to the package root, of the metadata file for a resource
+func (mk *MetadataKind) MetadataPath(relPath string) string {
+	fName := filepath.Base(relPath)
+	bName := fName[:len(fName)-len(filepath.Ext(fName))]
+	return mk.Dir + "/" + bName + mk.Ext
+}
+
+// ResourceKind describes a category of textures in a package
+type ResourceKind struct {
+	// unique name of the kind
+	Name string
+	// path prefix relative to the package root, e.g. "textures/walls/"
+	Prefix string
+	// if resources of this kind can be tagged
+	Taggable bool
+	// metadata file details, nil if the kind has no metadata
+	Metadata *MetadataKind
+	// builds a thumbnail for a resource, nil uses ddimage.DefaultThumbnail
+	Thumbnail func(img image.Image) image.Image
+}
+
+// MakeThumbnail builds a thumbnail for a resource of this kind
+func (rk *ResourceKind) MakeThumbnail(img image.Image) image.Image {
+	if rk == nil || rk.Thumbnail == nil {
+		return ddimage.DefaultThumbnail(img)
+	}
+	return rk.Thumbnail(img)
+}
+
+// names of the built in resource kinds
+const (
+	KindCave     = "caves"
+	KindLight    = "lights"
+	KindMaterial = "materials"
+	KindObject   = "objects"
+	KindPath     = "paths"
+	KindPattern  = "patterns"
+	KindPortal   = "portals"
+	KindRoof     = "roofs"
+	KindTerrain  = "terrain"
+	KindTileset  = "tilesets"
+	KindWall     = "walls"
+)
+
+var (
+	kindsLock     sync.RWMutex // guards resourceKinds
+	resourceKinds = []ResourceKind{
+		{Name: KindCave, Prefix: "textures/caves/"},
+		{Name: KindLight, Prefix: "textures/lights/"},
+		{Name: KindMaterial, Prefix: "textures/materials/"},
+		{Name: KindObject, Prefix: "textures/objects/", Taggable: true},
+		{Name: KindPath, Prefix: "textures/paths/", Thumbnail: ddimage.PathThumbnail},
+		{Name: KindPattern, Prefix: "textures/patterns/"},
+		{Name: KindPortal, Prefix: "textures/portals/"},
+		{Name: KindRoof, Prefix: "textures/roofs/"},
+		{Name: KindTerrain, Prefix: "textures/terrain/", Thumbnail: ddimage.TerrainThumbnail},
+		{
+			Name:   KindTileset,
+			Prefix: "textures/tilesets/",
+			Metadata: &MetadataKind{
+				Dir: "data/tilesets",
+				Ext: ".dungeondraft_tileset",
+				New: func() any { return NewPackageTileset() },
+			},
+		},
+		{
+			Name:   KindWall,
+			Prefix: "textures/walls/",
+			Metadata: &MetadataKind{
+				Dir: "data/walls",
+				Ext: ".dungeondraft_wall",
+				New: func() any { return NewPackageWall() },
+				Required: func(relPath string) bool {
+					// wall end caps share the metadata of their wall
+					return !strings.HasSuffix(strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath)), "_end")
+				},
+			},
+			Thumbnail: ddimage.WallThumbnail,
+		},
+	}
+)
+
+// RegisterResourceKind adds a resource kind, replacing any registered kind with the same name
+func RegisterResourceKind(kind ResourceKind) {
+	kindsLock.Lock()
+	defer kindsLock.Unlock()
+	index := slices.IndexFunc(resourceKinds, func(rk ResourceKind) bool {
+		return rk.Name == kind.Name
+	})
+	if index != -1 {
+		resourceKinds[index] = kind
+	} else {
+		resourceKinds = append(resourceKinds, kind)
+	}
+}
+
+// ResourceKinds returns a copy of the registered resource kinds
+func ResourceKinds() []ResourceKind {
+	kindsLock.RLock()
+	defer kindsLock.RUnlock()
+	return slices.Clone(resourceKinds)
+}
+
+// GetResourceKind returns the registered kind with a name or nil
+func GetResourceKind(name string) *ResourceKind {
+	kindsLock.RLock()
+	defer kindsLock.RUnlock()
+	for _, rk := range resourceKinds {
+		if rk.Name == name {
+			return &rk
+		}
+	}
+	return nil
+}
+
+// ResourceKindFor returns the kind with the longest prefix matching a path relative to the package root, or nil
+func ResourceKindFor(relPath string) *ResourceKind {
+	kindsLock.RLock()
+	defer kindsLock.RUnlock()
+	var found *ResourceKind
+	for i := range resourceKinds {
+		rk := resourceKinds[i]
+		if strings.HasPrefix(relPath, rk.Prefix) && (found == nil || len(rk.Prefix) > len(found.Prefix)) {
+			found = &rk
+		}
+	}
+	return found
+}
+
+// MetadataKindFor returns the kind whose metadata files live at a path relative to the package root, or nil
+func MetadataKindFor(relPath string) *ResourceKind {
+	kindsLock.RLock()
+	defer kindsLock.RUnlock()
+	for _, rk := range resourceKinds {
+		if rk.Metadata != nil &&
+			strings.HasPrefix(relPath, rk.Metadata.Dir+"/") &&
+			strings.HasSuffix(relPath, rk.Metadata.Ext) {
+			return &rk
+		}
+	}
+	return nil
+}
@@ -19,6 +19,19 @@ type PackageInfo struct {
 	Keywords       []string             `json:"-"`
 	Allow3rdParty  *bool                `json:"allow_3rd_party_mapping_software_to_read,omitempty"`
 	ColorOverrides CustomColorOverrides `json:"custom_color_overrides,omitempty"`
+
+	// fields of the pack.json not covered above
+	Extra `json:"-"`
+}
+
+func (info *PackageInfo) UnmarshalJSON(data []byte) error {
+	type packageInfo PackageInfo
+	return UnmarshalObject(data, (*packageInfo)(info), &info.Extra)
+}
+
+func (info PackageInfo) MarshalJSON() ([]byte, error) {
+	type packageInfo PackageInfo
+	return MarshalObject(packageInfo(info), &info.Extra)
 }
 
 type CustomColorOverrides struct {
@@ -82,6 +95,18 @@ type PackageTileset struct {
 	Type TilesetType `json:"type"`
 	// default color
 	Color color.Color `json:"color"`
+
+	Extra `json:"-"`
+}
+
+func (ts *PackageTileset) UnmarshalJSON(data []byte) error {
+	type packageTileset PackageTileset
+	return UnmarshalObject(data, (*packageTileset)(ts), &ts.Extra)
+}
+
+func (ts PackageTileset) MarshalJSON() ([]byte, error) {
+	type packageTileset PackageTileset
+	return MarshalObject(packageTileset(ts), &ts.Extra)
 }
 
 func NewPackageTileset() *PackageTileset {
@@ -93,6 +118,18 @@ type PackageWall struct {
 	Path string `json:"path"`
 	// default color
 	Color color.Color `json:"color"`
+
+	Extra `json:"-"`
+}
+
+func (w *PackageWall) UnmarshalJSON(data []byte) error {
+	type packageWall PackageWall
+	return UnmarshalObject(data, (*packageWall)(w), &w.Extra)
+}
+
+func (w PackageWall) MarshalJSON() ([]byte, error) {
+	type packageWall PackageWall
+	return MarshalObject(packageWall(w), &w.Extra)
 }
 
 func NewPackageWall() *PackageWall {
@@ -139,6 +139,10 @@ func (s *Set[T]) UnmarshalJSON(bytes []byte) error {
 
 func (s *Set[T]) MarshalJSON() ([]byte, error) {
 	data := slices.Sorted(maps.Keys(s.data))
+	if data == nil {
+		// an empty set is an empty array, not null
+		data = []T{}
+	}
 	return json.Marshal(data)
 }
 
@@ -0,0 +1,347 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/ryex/dungeondraft-gopackager/internal/utils"
+)
+
+var ErrBadTagQuery = errors.New("could not parse tag query")
+
+// TagQuery is a boolean query over the tags of a resource, parsed with ParseTagQuery
+type TagQuery struct {
+	query string
+	root  tagQueryNode
+}
+
+type tagQueryNode interface {
+	match(pt *PackageTags, resource string) bool
+}
+
+type tagQueryAnd []tagQueryNode
+
+func (q tagQueryAnd) match(pt *PackageTags, resource string) bool {
+	for _, node := range q {
+		if !node.match(pt, resource) {
+			return false
+		}
+	}
+	return true
+}
+
+type tagQueryOr []tagQueryNode
+
+func (q tagQueryOr) match(pt *PackageTags, resource string) bool {
+	for _, node := range q {
+		if node.match(pt, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+type tagQueryNot struct {
+	node tagQueryNode
+}
+
+func (q tagQueryNot) match(pt *PackageTags, resource string) bool {
+	return !q.node.match(pt, resource)
+}
+
+// resource has a tag with a matching name
+type tagQueryTag struct {
+	name *regexp.Regexp
+}
+
+func (q tagQueryTag) match(pt *PackageTags, resource string) bool {
+	for tag, resources := range pt.Tags {
+		if q.name.MatchString(tag) && resources.Has(resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// resource has a tag in a set with a matching name
+type tagQuerySet struct {
+	name *regexp.Regexp
+}
+
+func (q tagQuerySet) match(pt *PackageTags, resource string) bool {
+	for set, tags := range pt.Sets {
+		if !q.name.MatchString(set) {
+			continue
+		}
+		for tag := range tags.Values() {
+			if resources, ok := pt.Tags[tag]; ok && resources.Has(resource) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resource path matches a glob
+type tagQueryPath struct {
+	glob *regexp.Regexp
+}
+
+func (q tagQueryPath) match(_ *PackageTags, resource string) bool {
+	return q.glob.MatchString(resource)
+}
+
+// resource has no tags
+type tagQueryUntagged struct{}
+
+func (q tagQueryUntagged) match(pt *PackageTags, resource string) bool {
+	for _, resources := range pt.Tags {
+		if resources.Has(resource) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseTagQuery parses a boolean query over the tags of a resource, e.g.
+//
+//	Furniture AND (Wood OR Metal) AND NOT Broken
+//
+// terms are combined with AND, OR, NOT and parentheses, AND binding tighter than OR.
+// a term is a tag name, set:<set name> for a tag in a set, path:<glob> for a resource path
+// relative to the package root, or is:untagged and is:tagged.
+// tag and set names ignore case, may use * as a wildcard, and may hold spaces,
+// words next to each other are one name. quote a name that is also an operator ("Or")
+func ParseTagQuery(query string) (*TagQuery, error) {
+	tokens, err := lexTagQuery(query)
+	if err != nil {
+		return nil, errors.Join(err, ErrBadTagQuery)
+	}
+	if len(tokens) == 0 {
+		return nil, errors.Join(errors.New("empty tag query"), ErrBadTagQuery)
+	}
+	p := &tagQueryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected '%s' at position %d", p.tokens[p.pos].text, p.tokens[p.pos].pos+1)
+	}
+	if err != nil {
+		return nil, errors.Join(err, ErrBadTagQuery)
+	}
+	return &TagQuery{query: query, root: root}, nil
+}
+
+func (q *TagQuery) String() string {
+	return q.query
+}
+
+// Match reports if a resource, by path relative to the package root, matches the query
+func (q *TagQuery) Match(pt *PackageTags, resource string) bool {
+	return q.root.match(pt, utils.CleanRelativeResourcePath(resource))
+}
+
+type tagQueryTokenKind int
+
+const (
+	tagQueryWord tagQueryTokenKind = iota
+	tagQueryAndOp
+	tagQueryOrOp
+	tagQueryNotOp
+	tagQueryOpen
+	tagQueryClose
+)
+
+type tagQueryToken struct {
+	kind tagQueryTokenKind
+	text string
+	// the word started with a quote, so it is never an operator or a prefixed term
+	quoted bool
+	pos    int
+}
+
+// lexTagQuery splits a query into parentheses, operators, and words.
+// a word runs to the next space or parenthesis outside of double quotes, \" is a literal quote
+func lexTagQuery(query string) ([]tagQueryToken, error) {
+	var tokens []tagQueryToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, tagQueryToken{kind: tagQueryOpen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, tagQueryToken{kind: tagQueryClose, text: ")", pos: i})
+			i++
+		default:
+			start := i
+			var word strings.Builder
+			inQuote := false
+			for ; i < len(runes); i++ {
+				r := runes[i]
+				if !inQuote && (unicode.IsSpace(r) || r == '(' || r == ')') {
+					break
+				}
+				switch {
+				case r == '\\' && inQuote && i+1 < len(runes) && runes[i+1] == '"':
+					word.WriteRune('"')
+					i++
+				case r == '"':
+					inQuote = !inQuote
+				default:
+					word.WriteRune(r)
+				}
+			}
+			if inQuote {
+				return nil, fmt.Errorf("unterminated quote at position %d", start+1)
+			}
+			token := tagQueryToken{kind: tagQueryWord, text: word.String(), quoted: runes[start] == '"', pos: start}
+			if !token.quoted {
+				switch strings.ToUpper(token.text) {
+				case "AND":
+					token.kind = tagQueryAndOp
+				case "OR":
+					token.kind = tagQueryOrOp
+				case "NOT":
+					token.kind = tagQueryNotOp
+				}
+			}
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens, nil
+}
+
+type tagQueryParser struct {
+	tokens []tagQueryToken
+	pos    int
+}
+
+func (p *tagQueryParser) peek(kind tagQueryTokenKind) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind
+}
+
+func (p *tagQueryParser) parseOr() (tagQueryNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := tagQueryOr{node}
+	for p.peek(tagQueryOrOp) {
+		p.pos++
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *tagQueryParser) parseAnd() (tagQueryNode, error) {
+	node, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	nodes := tagQueryAnd{node}
+	for p.peek(tagQueryAndOp) {
+		p.pos++
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *tagQueryParser) parseNot() (tagQueryNode, error) {
+	if p.peek(tagQueryNotOp) {
+		p.pos++
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return tagQueryNot{node}, nil
+	}
+	return p.parseTerm()
+}
+
+func (p *tagQueryParser) parseTerm() (tagQueryNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of query")
+	}
+	token := p.tokens[p.pos]
+	switch token.kind {
+	case tagQueryOpen:
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(tagQueryClose) {
+			return nil, fmt.Errorf("missing ')' for '(' at position %d", token.pos+1)
+		}
+		p.pos++
+		return node, nil
+	case tagQueryWord:
+	default:
+		return nil, fmt.Errorf("unexpected '%s' at position %d", token.text, token.pos+1)
+	}
+
+	// words next to each other are one name
+	words := []string{token.text}
+	p.pos++
+	for p.peek(tagQueryWord) {
+		words = append(words, p.tokens[p.pos].text)
+		p.pos++
+	}
+	name := strings.Join(words, " ")
+
+	if !token.quoted {
+		prefix, value, ok := strings.Cut(name, ":")
+		switch strings.ToLower(prefix) {
+		case "set":
+			if ok && value != "" {
+				return tagQuerySet{tagQueryNameRegexp(value)}, nil
+			}
+		case "path":
+			if ok && value != "" {
+				glob, err := GlobToRelPathRegexp(value)
+				if err != nil {
+					return nil, errors.Join(err, fmt.Errorf("bad glob '%s' at position %d", value, token.pos+1))
+				}
+				return tagQueryPath{glob}, nil
+			}
+		case "is":
+			switch strings.ToLower(value) {
+			case "untagged":
+				return tagQueryUntagged{}, nil
+			case "tagged":
+				return tagQueryNot{tagQueryUntagged{}}, nil
+			}
+			return nil, fmt.Errorf("unknown term '%s' at position %d, expected is:untagged or is:tagged", name, token.pos+1)
+		}
+	}
+	return tagQueryTag{tagQueryNameRegexp(name)}, nil
+}
+
+// tagQueryNameRegexp matches a tag or set name ignoring case, with * as a wildcard
+func tagQueryNameRegexp(name string) *regexp.Regexp {
+	parts := strings.Split(name, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("(?i)^" + strings.Join(parts, ".*") + "$")
+}
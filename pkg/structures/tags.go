@@ -132,6 +132,33 @@ func (pt *PackageTags) DeleteSet(set string) {
 	delete(pt.Sets, set)
 }
 
+// RenameTag renames a tag, keeping its resources and the sets it is in.
+// renaming to an existing tag merges the two
+func (pt *PackageTags) RenameTag(from string, to string) {
+	pt.MergeTags(to, from)
+}
+
+// MergeTags moves the resources of the from tags to the into tag and deletes them,
+// every set holding one of the from tags holds the into tag instead
+func (pt *PackageTags) MergeTags(into string, from ...string) {
+	for _, tag := range from {
+		if tag == into {
+			continue
+		}
+		if s, ok := pt.Tags[tag]; ok {
+			pt.AddTag(into)
+			pt.Tags[into].AddM(s.AsSlice()...)
+			pt.DeleteTag(tag)
+		}
+		for _, s := range pt.Sets {
+			if s.Has(tag) {
+				s.Remove(tag)
+				s.Add(into)
+			}
+		}
+	}
+}
+
 func (pt *PackageTags) TagsFor(resources ...string) *Set[string] {
 	res := NewSet[string]()
 
@@ -152,6 +179,25 @@ func (pt *PackageTags) TagsFor(resources ...string) *Set[string] {
 	return res
 }
 
+// Filter returns a copy of the tags with only the resources that keep returns true for.
+// tags left without resources are dropped, along with their entries in sets
+func (pt *PackageTags) Filter(keep func(resource string) bool) *PackageTags {
+	res := NewPackageTags()
+	for tag, s := range pt.Tags {
+		kept := s.Filter(keep)
+		if kept.Size() > 0 {
+			res.Tags[tag] = kept
+		}
+	}
+	for set, s := range pt.Sets {
+		kept := s.Filter(res.TagExists)
+		if kept.Size() > 0 {
+			res.Sets[set] = kept
+		}
+	}
+	return res
+}
+
 func (pt *PackageTags) ClearTagsFor(resources ...string) {
 	relPaths := slices.Collect(utils.Map(slices.Values(resources), utils.CleanRelativeResourcePath))
 	for tag := range pt.Tags {
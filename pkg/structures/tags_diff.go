@@ -0,0 +1,119 @@
+package structures
+
+import (
+	"maps"
+	"slices"
+)
+
+// TagsDiff describes the changes needed to go from one PackageTags to another
+type TagsDiff struct {
+	// tags that only exist in the new tags
+	AddedTags []string
+	// tags that only exist in the old tags
+	RemovedTags []string
+	// map of tag names to resources that gained the tag
+	Tagged map[string][]string
+	// map of tag names to resources that lost the tag
+	Untagged map[string][]string
+
+	// sets that only exist in the new tags
+	AddedSets []string
+	// sets that only exist in the old tags
+	RemovedSets []string
+	// map of set names to tags added to the set
+	SetAdded map[string][]string
+	// map of set names to tags removed from the set
+	SetRemoved map[string][]string
+}
+
+func setOrEmpty(m map[string]*Set[string], key string) *Set[string] {
+	s, ok := m[key]
+	if !ok || s == nil {
+		return NewSet[string]()
+	}
+	return s
+}
+
+func diffSetMaps(
+	old, new map[string]*Set[string],
+) (added []string, removed []string, gained map[string][]string, lost map[string][]string) {
+	gained = make(map[string][]string)
+	lost = make(map[string][]string)
+
+	keys := SetFrom(slices.Collect(maps.Keys(old)))
+	keys.AddM(slices.Collect(maps.Keys(new))...)
+
+	for key := range keys.Values() {
+		_, inOld := old[key]
+		_, inNew := new[key]
+		if inNew && !inOld {
+			added = append(added, key)
+		} else if inOld && !inNew {
+			removed = append(removed, key)
+		}
+		oldSet := setOrEmpty(old, key)
+		newSet := setOrEmpty(new, key)
+		if plus := newSet.Difference(oldSet); plus.Size() > 0 {
+			gained[key] = slices.Sorted(plus.Values())
+		}
+		if minus := oldSet.Difference(newSet); minus.Size() > 0 {
+			lost[key] = slices.Sorted(minus.Values())
+		}
+	}
+	slices.Sort(added)
+	slices.Sort(removed)
+	return
+}
+
+// DiffPackageTags compares two sets of package tags.
+// a nil argument is treated as empty tags
+func DiffPackageTags(old, new *PackageTags) *TagsDiff {
+	if old == nil {
+		old = NewPackageTags()
+	}
+	if new == nil {
+		new = NewPackageTags()
+	}
+	diff := &TagsDiff{}
+	diff.AddedTags, diff.RemovedTags, diff.Tagged, diff.Untagged = diffSetMaps(old.Tags, new.Tags)
+	diff.AddedSets, diff.RemovedSets, diff.SetAdded, diff.SetRemoved = diffSetMaps(old.Sets, new.Sets)
+	return diff
+}
+
+// Empty reports if the diff contains no changes
+func (d *TagsDiff) Empty() bool {
+	return len(d.AddedTags) == 0 && len(d.RemovedTags) == 0 &&
+		len(d.Tagged) == 0 && len(d.Untagged) == 0 &&
+		len(d.AddedSets) == 0 && len(d.RemovedSets) == 0 &&
+		len(d.SetAdded) == 0 && len(d.SetRemoved) == 0
+}
+
+// ChangedTags lists every tag touched by the diff in sorted order
+func (d *TagsDiff) ChangedTags() []string {
+	tags := SetFrom(d.AddedTags)
+	tags.AddM(d.RemovedTags...)
+	tags.AddM(slices.Collect(maps.Keys(d.Tagged))...)
+	tags.AddM(slices.Collect(maps.Keys(d.Untagged))...)
+	return slices.Sorted(tags.Values())
+}
+
+// ChangedSets lists every set touched by the diff in sorted order
+func (d *TagsDiff) ChangedSets() []string {
+	sets := SetFrom(d.AddedSets)
+	sets.AddM(d.RemovedSets...)
+	sets.AddM(slices.Collect(maps.Keys(d.SetAdded))...)
+	sets.AddM(slices.Collect(maps.Keys(d.SetRemoved))...)
+	return slices.Sorted(sets.Values())
+}
+
+// Clone makes a deep copy of the package tags
+func (pt *PackageTags) Clone() *PackageTags {
+	res := NewPackageTags()
+	for tag, s := range pt.Tags {
+		res.Tags[tag] = s.Union(NewSet[string]())
+	}
+	for set, s := range pt.Sets {
+		res.Sets[set] = s.Union(NewSet[string]())
+	}
+	return res
+}
@@ -0,0 +1,94 @@
+package structures
+
+import (
+	"maps"
+	"slices"
+)
+
+// TagsMerge is the result of a three-way merge of package tags
+type TagsMerge struct {
+	Tags *PackageTags
+	// tags one side deleted while the other added resources to them
+	TagConflicts []TagsMergeConflict
+	// sets one side deleted while the other added tags to them
+	SetConflicts []TagsMergeConflict
+}
+
+// TagsMergeConflict is a tag or set one side deleted while the other side added to it,
+// the merged tags keep it with only the additions
+type TagsMergeConflict struct {
+	Name string
+	// ours deleted it and theirs added to it, otherwise the other way around
+	DeletedByOurs bool
+	// resources added to the tag, or tags added to the set
+	Added []string
+}
+
+// Conflicted reports if the merge had conflicts
+func (tm *TagsMerge) Conflicted() bool {
+	return len(tm.TagConflicts) > 0 || len(tm.SetConflicts) > 0
+}
+
+// MergePackageTags merges the changes ours and theirs made to base. each membership of a resource in a tag
+// and of a tag in a set is merged on its own, a change made by either side is kept
+func MergePackageTags(base *PackageTags, ours *PackageTags, theirs *PackageTags) *TagsMerge {
+	tm := &TagsMerge{Tags: NewPackageTags()}
+	tm.Tags.Tags, tm.TagConflicts = mergeSetMaps(base.Tags, ours.Tags, theirs.Tags)
+	tm.Tags.Sets, tm.SetConflicts = mergeSetMaps(base.Sets, ours.Sets, theirs.Sets)
+	return tm
+}
+
+// mergeSetMaps merges maps of names to sets, like tags to resources or sets to tags
+func mergeSetMaps(base, ours, theirs map[string]*Set[string]) (map[string]*Set[string], []TagsMergeConflict) {
+	merged := make(map[string]*Set[string])
+	var conflicts []TagsMergeConflict
+
+	names := NewSet[string]()
+	names.AddM(slices.Collect(maps.Keys(base))...)
+	names.AddM(slices.Collect(maps.Keys(ours))...)
+	names.AddM(slices.Collect(maps.Keys(theirs))...)
+	for _, name := range slices.Sorted(names.Values()) {
+		_, inBase := base[name]
+		_, inOurs := ours[name]
+		_, inTheirs := theirs[name]
+		b, o, t := setOrEmpty(base, name), setOrEmpty(ours, name), setOrEmpty(theirs, name)
+
+		values := NewSet[string]()
+		for _, s := range []*Set[string]{b, o, t} {
+			values.AddM(s.AsSlice()...)
+		}
+		s := NewSet[string]()
+		for value := range values.Values() {
+			keep := o.Has(value)
+			if keep != t.Has(value) {
+				// only one side changed the membership, the change wins
+				keep = !b.Has(value)
+			}
+			if keep {
+				s.Add(value)
+			}
+		}
+
+		if inBase && (!inOurs || !inTheirs) {
+			if !inOurs && !inTheirs {
+				continue
+			}
+			// deleted by one side, a conflict if the other side added to it
+			added := t
+			if inOurs {
+				added = o
+			}
+			added = added.Filter(func(value string) bool { return !b.Has(value) })
+			if added.Size() == 0 {
+				continue
+			}
+			conflicts = append(conflicts, TagsMergeConflict{
+				Name:          name,
+				DeletedByOurs: !inOurs,
+				Added:         slices.Sorted(added.Values()),
+			})
+		}
+		merged[name] = s
+	}
+	return merged, conflicts
+}
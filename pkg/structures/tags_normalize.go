@@ -0,0 +1,105 @@
+package structures
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// NormalizeTagName trims a tag and collapses runs of whitespace to a single space
+func NormalizeTagName(tag string) string {
+	return strings.Join(strings.Fields(tag), " ")
+}
+
+// singularCandidates are the possible singular forms of the last word of an english tag,
+// most likely first. none if the word does not look plural
+func singularCandidates(tag string) []string {
+	start := strings.LastIndexFunc(tag, unicode.IsSpace) + 1
+	word := []rune(tag[start:])
+	lower := strings.ToLower(string(word))
+	if len(word) <= 3 {
+		return nil
+	}
+	trim := func(n int) string {
+		return tag[:start] + string(word[:len(word)-n])
+	}
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(word) > 4:
+		// bodies to body
+		y := "y"
+		if unicode.IsUpper(word[len(word)-3]) {
+			y = "Y"
+		}
+		return []string{trim(3) + y}
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "shes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "xes"), strings.HasSuffix(lower, "zzes"):
+		// glasses, bushes, benches, boxes, but also caches and niches
+		return []string{trim(2), trim(1)}
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		// glass, cactus, debris are not plural
+		return nil
+	case strings.HasSuffix(lower, "s"):
+		return []string{trim(1)}
+	}
+	return nil
+}
+
+// SingularizeTagName turns the last word of an english tag from plural to singular,
+// e.g. "Wooden Chairs" to "Wooden Chair". the case of the tag is kept.
+// it only guesses from the word ending, so words like Canvas are wrongly cut short
+func SingularizeTagName(tag string) string {
+	if candidates := singularCandidates(tag); len(candidates) > 0 {
+		return candidates[0]
+	}
+	return tag
+}
+
+// NormalizeTags merges tags that only differ by surrounding or repeated whitespace or by case,
+// and if singularize is set plural tags into their singular. each group of tags is merged into
+// its most used spelling, plural tags into the most used spelling of the singular. plural tags
+// are only merged into a singular tag that exists, so tags that merely end in s, like Canvas
+// or Lens, are left alone.
+// returns a map of the changed tag names to the tag they were merged into
+func (pt *PackageTags) NormalizeTags(singularize bool) map[string]string {
+	groups := make(map[string][]string)
+	for tag := range pt.Tags {
+		k := strings.ToLower(NormalizeTagName(tag))
+		groups[k] = append(groups[k], tag)
+	}
+
+	into := make(map[string]string)
+	for k, group := range groups {
+		// most resources first, then by name so the result does not depend on map order
+		slices.SortFunc(group, func(a, b string) int {
+			return cmp.Or(cmp.Compare(pt.Tags[b].Size(), pt.Tags[a].Size()), cmp.Compare(a, b))
+		})
+		into[k] = NormalizeTagName(group[0])
+	}
+
+	if singularize {
+		for _, k := range slices.Sorted(maps.Keys(groups)) {
+			for _, singular := range singularCandidates(k) {
+				if _, ok := groups[singular]; ok && singular != k {
+					groups[singular] = append(groups[singular], groups[k]...)
+					delete(groups, k)
+					break
+				}
+			}
+		}
+	}
+
+	renamed := make(map[string]string)
+	for k, group := range groups {
+		var from []string
+		for _, tag := range group {
+			if tag != into[k] {
+				from = append(from, tag)
+				renamed[tag] = into[k]
+			}
+		}
+		pt.MergeTags(into[k], from...)
+	}
+	return renamed
+}